@@ -4,17 +4,29 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 
 	"github.com/gabrielvieirabra/payments-ledger/internal/config"
 	"github.com/gabrielvieirabra/payments-ledger/internal/database"
 	"github.com/gabrielvieirabra/payments-ledger/internal/handler"
+	"github.com/gabrielvieirabra/payments-ledger/internal/idempotency"
+	"github.com/gabrielvieirabra/payments-ledger/internal/logging"
+	"github.com/gabrielvieirabra/payments-ledger/internal/ratelimit"
+	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
+	"github.com/gabrielvieirabra/payments-ledger/internal/rpc"
+	"github.com/gabrielvieirabra/payments-ledger/internal/service"
+	"github.com/gabrielvieirabra/payments-ledger/internal/telemetry"
 	"github.com/gabrielvieirabra/payments-ledger/internal/worker"
 )
 
@@ -25,15 +37,27 @@ func main() {
 		os.Exit(1)
 	}
 
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: cfg.LogLevel(),
-	}))
+	})))
 	slog.SetDefault(logger)
 
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	shutdownTracing, err := telemetry.Setup(context.Background(), telemetry.Options{
+		ServiceName: cfg.AppName,
+		Environment: cfg.Environment,
+		Exporter:    cfg.TraceExporter,
+		FilePath:    cfg.TraceFile,
+		SampleRatio: cfg.TraceSampleRatio,
+	})
+	if err != nil {
+		slog.Error("failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
 	if err := database.RunMigrations(cfg.DatabaseURL, cfg.MigrationsPath); err != nil {
 		slog.Error("failed to run migrations", "error", err)
 		os.Exit(1)
@@ -48,9 +72,63 @@ func main() {
 	defer pool.Close()
 
 	wp := worker.NewPool(cfg.WorkerPoolSize, cfg.WorkerQueueSize)
+	prometheus.MustRegister(database.NewPoolCollector(pool), wp)
 	defer wp.Shutdown()
 
-	router := handler.NewRouter(pool, wp)
+	accountRepo := repository.NewAccountRepository(pool)
+	entryRepo := repository.NewEntryRepository(pool)
+	transactionRepo := repository.NewTransactionRepository(pool)
+
+	svcs := handler.Services{
+		Accounts:     service.NewAccountService(accountRepo),
+		Entries:      service.NewEntryService(entryRepo),
+		Transactions: service.NewTransactionService(accountRepo, entryRepo, transactionRepo, wp, cfg.AuthorizationTTL),
+		Journal:      service.NewJournalService(accountRepo, entryRepo, transactionRepo, wp),
+		Balances:     service.NewBalanceService(accountRepo, entryRepo),
+	}
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	var jobs sync.WaitGroup
+	jobs.Go(func() {
+		worker.Every(jobsCtx, cfg.AuthorizationExpiryInterval, "expire_authorizations", svcs.Transactions.ExpireAuthorizations)
+	})
+
+	var idempotencyStore idempotency.Store = repository.NewIdempotencyRepository(pool, cfg.IdempotencyKeyTTL)
+	if cfg.IdempotencyStore == idempotency.StoreMemory {
+		idempotencyStore = idempotency.NewMemoryStore(cfg.IdempotencyKeyTTL, cfg.IdempotencyMemoryCapacity)
+	}
+	slog.Info("idempotency store selected", "store", cfg.IdempotencyStore)
+
+	janitor := idempotency.NewJanitor(idempotencyStore, cfg.IdempotencyPurgeBatch)
+	jobs.Go(func() {
+		worker.Every(jobsCtx, cfg.IdempotencyPurgeInterval, "purge_idempotency_keys", janitor.Purge)
+	})
+
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimitStore == ratelimit.StorePostgres {
+		rateLimitRepo := repository.NewRateLimitRepository(pool)
+		rateLimitStore = rateLimitRepo
+		jobs.Go(func() {
+			worker.Every(jobsCtx, cfg.RateLimitPurgeInterval, "purge_rate_limit_buckets", func(ctx context.Context) error {
+				_, err := rateLimitRepo.DeleteFull(ctx, cfg.RateLimitPurgeBatch)
+				return err
+			})
+		})
+	}
+	slog.Info("rate limit store selected", "store", cfg.RateLimitStore, "enabled", cfg.RateLimitEnabled)
+
+	clientRepo := repository.NewAPIClientRepository(pool)
+	if cfg.AuthEnabled {
+		warnIfNoAPIClients(ctx, clientRepo)
+	} else {
+		slog.Warn("authentication is disabled, every request is accepted")
+	}
+	router := handler.NewRouter(pool, svcs, handler.Stores{
+		Idempotency: idempotencyStore,
+		Clients:     clientRepo,
+		Secrets:     clientRepo,
+		RateLimits:  rateLimitStore,
+	}, cfg)
 
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Port),
@@ -68,6 +146,35 @@ func main() {
 		}
 	}()
 
+	var grpcSrv *grpc.Server
+	var healthSrv *health.Server
+	if cfg.GRPCPort > 0 {
+		opts := rpc.Options{}
+		if cfg.AuthEnabled {
+			opts.Clients = clientRepo
+		}
+		if cfg.RateLimitEnabled {
+			opts.RateLimits = rateLimitStore
+			opts.RatePolicy = ratelimit.Policy{Default: cfg.RateLimitClient, Routes: cfg.RateLimitRoutes}
+			opts.AccountLimit = cfg.RateLimitAccount
+		}
+		ledger := rpc.NewLedgerServer(svcs.Accounts, svcs.Entries, svcs.Transactions, idempotencyStore, cfg.IdempotencyLockTimeout)
+		grpcSrv, healthSrv = rpc.NewServer(ledger, opts)
+
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+		if err != nil {
+			slog.Error("failed to listen for grpc", "port", cfg.GRPCPort, "error", err)
+			os.Exit(1)
+		}
+		go func() {
+			slog.Info("grpc server starting", "port", cfg.GRPCPort)
+			if err := grpcSrv.Serve(lis); err != nil {
+				slog.Error("grpc server failed", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	sig := <-quit
@@ -76,10 +183,55 @@ func main() {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
+	// Both servers drain in parallel within the same deadline
+	grpcStopped := make(chan struct{})
+	go func() {
+		if grpcSrv != nil {
+			// Fail health checks first, so that balancers stop routing new calls
+			healthSrv.Shutdown()
+			grpcSrv.GracefulStop()
+		}
+		close(grpcStopped)
+	}()
+
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		slog.Error("server forced to shutdown", "error", err)
 		os.Exit(1)
 	}
 
+	// GracefulStop waits for every call, so cut the rest off at the deadline
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		if grpcSrv != nil {
+			grpcSrv.Stop()
+		}
+	}
+
+	// Background jobs submit to the worker pool, so stop them before it shuts down
+	stopJobs()
+	jobs.Wait()
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("failed to flush traces", "error", err)
+	}
+
 	slog.Info("server stopped gracefully")
 }
+
+// warnIfNoAPIClients flags a deployment that will answer 401 to every call
+// because no API key has been issued yet, e.g. right after upgrading.
+func warnIfNoAPIClients(ctx context.Context, clients *repository.APIClientRepository) {
+	list, err := clients.List(ctx)
+	if err != nil {
+		slog.Error("failed to list api clients", "error", err)
+		return
+	}
+	for _, c := range list {
+		if c.RevokedAt == nil {
+			return
+		}
+	}
+	slog.Warn("authentication is enabled but no active API client exists, every request will get 401; " +
+		"create a key with `make apiclient NAME=<name>` or set AUTH_ENABLED=false")
+}
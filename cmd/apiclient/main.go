@@ -0,0 +1,150 @@
+// Command apiclient manages the API clients allowed to call the ledger.
+//
+//	apiclient create -name <name> [-role viewer|processor|operator]
+//	                                create a client and print its key once
+//	apiclient list                  list clients
+//	apiclient revoke -name <name>   revoke a client's key
+//	apiclient add-secret -name <name>
+//	                                add a request signing secret and print it once
+//	apiclient revoke-secret -id <secret id>
+//	                                revoke a signing secret once callers have rotated
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/auth"
+	"github.com/gabrielvieirabra/payments-ledger/internal/config"
+	"github.com/gabrielvieirabra/payments-ledger/internal/database"
+	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: apiclient <create|list|revoke|add-secret|revoke-secret> [flags]")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pool, err := database.NewPool(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	repo := repository.NewAPIClientRepository(pool)
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("create", flag.ContinueOnError)
+		name := fs.String("name", "", "client name")
+		role := fs.String("role", auth.RoleViewer, "client role: viewer, processor or operator")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *name == "" {
+			return errors.New("-name is required")
+		}
+		if !auth.ValidRole(*role) {
+			return fmt.Errorf("unknown role %q", *role)
+		}
+		key, prefix, err := auth.GenerateKey()
+		if err != nil {
+			return err
+		}
+		client, err := repo.Create(ctx, *name, *role, prefix, auth.HashKey(key))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("client:  %s (%s)\n", client.Name, client.ID)
+		fmt.Printf("role:    %s\n", client.Role)
+		fmt.Printf("api key: %s\n", key)
+		fmt.Println("Store the key now, it cannot be shown again.")
+	case "list":
+		clients, err := repo.List(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tROLE\tKEY PREFIX\tCREATED\tREVOKED")
+		for _, c := range clients {
+			revoked := "-"
+			if c.RevokedAt != nil {
+				revoked = c.RevokedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", c.ID, c.Name, c.Role, c.KeyPrefix, c.CreatedAt.Format(time.RFC3339), revoked)
+		}
+		return w.Flush()
+	case "revoke":
+		fs := flag.NewFlagSet("revoke", flag.ContinueOnError)
+		name := fs.String("name", "", "client name")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *name == "" {
+			return errors.New("-name is required")
+		}
+		if err := repo.Revoke(ctx, *name); err != nil {
+			return err
+		}
+		fmt.Printf("revoked %s\n", *name)
+	case "add-secret":
+		fs := flag.NewFlagSet("add-secret", flag.ContinueOnError)
+		name := fs.String("name", "", "client name")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *name == "" {
+			return errors.New("-name is required")
+		}
+		secret, err := auth.GenerateSecret()
+		if err != nil {
+			return err
+		}
+		id, err := repo.AddSecret(ctx, *name, secret)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("secret id: %s\n", id)
+		fmt.Printf("secret:    %s\n", secret)
+		fmt.Println("Store the secret now, it cannot be shown again.")
+	case "revoke-secret":
+		fs := flag.NewFlagSet("revoke-secret", flag.ContinueOnError)
+		id := fs.String("id", "", "secret id")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		secretID, err := uuid.Parse(*id)
+		if err != nil {
+			return fmt.Errorf("invalid -id: %w", err)
+		}
+		if err := repo.RevokeSecret(ctx, secretID); err != nil {
+			return err
+		}
+		fmt.Printf("revoked secret %s\n", secretID)
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+// Command openapi prints the OpenAPI document of the HTTP API, the same one
+// served at /api/v1/openapi.json.
+//
+//	go run ./cmd/openapi > api/openapi.json
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/handler"
+)
+
+func main() {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(handler.OpenAPI()); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,4 @@
+# Get daily balance history for an account
+# GET /api/v1/accounts/:id/balance-history?from=&to=&interval=
+
+curl -s -H "Authorization: Bearer $API_KEY" "http://localhost:8080/api/v1/accounts/ACCOUNT_UUID_HERE/balance-history?from=2025-01-01T00:00:00Z&to=2025-02-01T00:00:00Z&interval=day" | jq
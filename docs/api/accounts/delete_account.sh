@@ -1,4 +1,4 @@
 # Delete account by ID
 # DELETE /api/v1/accounts/:id
 
-curl -s -X DELETE http://localhost:8080/api/v1/accounts/ACCOUNT_UUID_HERE | jq
+curl -s -H "Authorization: Bearer $API_KEY" -X DELETE http://localhost:8080/api/v1/accounts/ACCOUNT_UUID_HERE | jq
@@ -1,4 +1,4 @@
 # Get account by ID
 # GET /api/v1/accounts/:id
 
-curl -s http://localhost:8080/api/v1/accounts/ACCOUNT_UUID_HERE | jq
+curl -s -H "Authorization: Bearer $API_KEY" http://localhost:8080/api/v1/accounts/ACCOUNT_UUID_HERE | jq
@@ -0,0 +1,4 @@
+# Get account balance at a point in time
+# GET /api/v1/accounts/:id/balance?as_of=
+
+curl -s -H "Authorization: Bearer $API_KEY" "http://localhost:8080/api/v1/accounts/ACCOUNT_UUID_HERE/balance?as_of=2025-01-02T00:00:00Z" | jq
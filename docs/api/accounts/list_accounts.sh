@@ -1,4 +1,7 @@
-# List accounts (paginated)
-# GET /api/v1/accounts?limit=10&offset=0
+# List accounts (cursor-paginated)
+# GET /api/v1/accounts?limit=10
 
-curl -s http://localhost:8080/api/v1/accounts?limit=10&offset=0 | jq
+curl -s -H "Authorization: Bearer $API_KEY" http://localhost:8080/api/v1/accounts?limit=10 | jq
+
+# Next page: pass the next_cursor value from the previous response
+curl -s -H "Authorization: Bearer $API_KEY" "http://localhost:8080/api/v1/accounts?limit=10&cursor=NEXT_CURSOR_HERE" | jq
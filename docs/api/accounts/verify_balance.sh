@@ -0,0 +1,4 @@
+# Check that the latest entry's running balance matches the account balance
+# GET /api/v1/accounts/:id/balance/verify
+
+curl -s -H "Authorization: Bearer $API_KEY" http://localhost:8080/api/v1/accounts/ACCOUNT_UUID_HERE/balance/verify | jq
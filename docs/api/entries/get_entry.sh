@@ -1,4 +1,4 @@
 # Get entry by ID
 # GET /api/v1/entries/:id
 
-curl -s http://localhost:8080/api/v1/entries/ENTRY_UUID_HERE | jq
+curl -s -H "Authorization: Bearer $API_KEY" http://localhost:8080/api/v1/entries/ENTRY_UUID_HERE | jq
@@ -3,6 +3,7 @@
 # 200 requests, 10 concurrent workers, 5s timeout
 
 hey -n 200 -c 10 -t 5 \
+  -H "Authorization: Bearer ${API_KEY:?Set API_KEY env var}" \
   -m POST \
   -H "Content-Type: application/json" \
   -d '{"owner":"stress-test-user","currency":"BRL"}' \
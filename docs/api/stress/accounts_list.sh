@@ -3,4 +3,5 @@
 # 1000 requests, 50 concurrent workers, 5s timeout
 
 hey -n 1000 -c 50 -t 5 \
-  http://localhost:8080/api/v1/accounts?limit=10&offset=0
+  -H "Authorization: Bearer ${API_KEY:?Set API_KEY env var}" \
+  http://localhost:8080/api/v1/accounts?limit=10
@@ -7,4 +7,5 @@
 ACCOUNT_ID="${ACCOUNT_ID:?Set ACCOUNT_ID env var}"
 
 hey -n 1000 -c 50 -t 5 \
-  "http://localhost:8080/api/v1/accounts/${ACCOUNT_ID}/entries?limit=10&offset=0"
+  -H "Authorization: Bearer ${API_KEY:?Set API_KEY env var}" \
+  "http://localhost:8080/api/v1/accounts/${ACCOUNT_ID}/entries?limit=10"
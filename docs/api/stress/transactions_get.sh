@@ -7,4 +7,5 @@
 TRANSACTION_ID="${TRANSACTION_ID:?Set TRANSACTION_ID env var}"
 
 hey -n 1000 -c 50 -t 5 \
+  -H "Authorization: Bearer ${API_KEY:?Set API_KEY env var}" \
   http://localhost:8080/api/v1/transactions/${TRANSACTION_ID}
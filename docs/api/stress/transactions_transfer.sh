@@ -9,6 +9,7 @@ FROM_ACCOUNT_ID="${FROM_ACCOUNT_ID:?Set FROM_ACCOUNT_ID env var}"
 TO_ACCOUNT_ID="${TO_ACCOUNT_ID:?Set TO_ACCOUNT_ID env var}"
 
 hey -n 500 -c 20 -t 10 \
+  -H "Authorization: Bearer ${API_KEY:?Set API_KEY env var}" \
   -m POST \
   -H "Content-Type: application/json" \
   -d "{\"from_account_id\":\"${FROM_ACCOUNT_ID}\",\"to_account_id\":\"${TO_ACCOUNT_ID}\",\"amount\":1,\"currency\":\"BRL\"}" \
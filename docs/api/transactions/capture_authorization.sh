@@ -0,0 +1,4 @@
+# Capture a pending authorization
+# POST /api/v1/transactions/:id/capture
+
+curl -s -H "Authorization: Bearer $API_KEY" -X POST http://localhost:8080/api/v1/transactions/TRANSACTION_UUID_HERE/capture | jq
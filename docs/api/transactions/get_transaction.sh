@@ -1,4 +1,4 @@
 # Get transaction by ID
 # GET /api/v1/transactions/:id
 
-curl -s http://localhost:8080/api/v1/transactions/TRANSACTION_UUID_HERE | jq
+curl -s -H "Authorization: Bearer $API_KEY" http://localhost:8080/api/v1/transactions/TRANSACTION_UUID_HERE | jq
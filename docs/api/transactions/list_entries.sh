@@ -0,0 +1,4 @@
+# List the entries (legs) posted by a transaction
+# GET /api/v1/transactions/:id/entries
+
+curl -s -H "Authorization: Bearer $API_KEY" http://localhost:8080/api/v1/transactions/TRANSACTION_UUID_HERE/entries | jq
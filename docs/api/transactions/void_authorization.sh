@@ -0,0 +1,4 @@
+# Void a pending authorization and release its hold
+# POST /api/v1/transactions/:id/void
+
+curl -s -H "Authorization: Bearer $API_KEY" -X POST http://localhost:8080/api/v1/transactions/TRANSACTION_UUID_HERE/void | jq
@@ -0,0 +1,60 @@
+// Package auth carries the authenticated API client through request contexts
+// and handles API key generation and hashing.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+)
+
+const (
+	keyPrefix    = "pl_"
+	keyBytes     = 32
+	prefixLength = len(keyPrefix) + 8
+)
+
+type clientKey struct{}
+
+func WithClient(ctx context.Context, client domain.APIClient) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+func ClientFromContext(ctx context.Context) (domain.APIClient, bool) {
+	client, ok := ctx.Value(clientKey{}).(domain.APIClient)
+	return client, ok
+}
+
+// ClientID returns the ID of the authenticated client, or nil for anonymous
+// requests (authentication disabled, background jobs).
+func ClientID(ctx context.Context) *uuid.UUID {
+	client, ok := ClientFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return &client.ID
+}
+
+// GenerateKey returns a new random API key and its display prefix.
+func GenerateKey() (key, prefix string, err error) {
+	buf := make([]byte, keyBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("generate api key: %w", err)
+	}
+	key = keyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return key, key[:prefixLength], nil
+}
+
+// HashKey is how keys are stored and looked up. Keys carry 256 bits of
+// entropy, so a plain SHA-256 is enough; no salt or slow hash is needed.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+)
+
+func TestGenerateKey(t *testing.T) {
+	key, prefix, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(key, prefix) || !strings.HasPrefix(prefix, "pl_") {
+		t.Errorf("unexpected key %q with prefix %q", key, prefix)
+	}
+
+	other, _, _ := GenerateKey()
+	if key == other {
+		t.Error("expected distinct keys")
+	}
+}
+
+func TestHashKey(t *testing.T) {
+	if HashKey("a") == HashKey("b") {
+		t.Error("expected distinct hashes")
+	}
+	if len(HashKey("a")) != 64 {
+		t.Errorf("expected hex sha256, got %q", HashKey("a"))
+	}
+}
+
+func TestClientID(t *testing.T) {
+	if ClientID(context.Background()) != nil {
+		t.Error("expected nil client ID for anonymous context")
+	}
+
+	id := uuid.New()
+	ctx := WithClient(context.Background(), domain.APIClient{ID: id})
+	if got := ClientID(ctx); got == nil || *got != id {
+		t.Errorf("expected %s, got %v", id, got)
+	}
+}
@@ -0,0 +1,45 @@
+package auth
+
+import "slices"
+
+type Permission string
+
+const (
+	PermAccountsRead     Permission = "accounts:read"
+	PermAccountsWrite    Permission = "accounts:write"
+	PermAccountsAdmin    Permission = "accounts:admin"
+	PermTransfersWrite   Permission = "transfers:write"
+	PermTransactionsRead Permission = "transactions:read"
+)
+
+const (
+	RoleViewer    = "viewer"
+	RoleProcessor = "processor"
+	RoleOperator  = "operator"
+)
+
+// rolePermissions grants each role its permissions. Viewers back read-only
+// dashboards, processors move money on behalf of customers and operators
+// additionally freeze, close and delete accounts.
+var rolePermissions = map[string][]Permission{
+	RoleViewer: {PermAccountsRead, PermTransactionsRead},
+	RoleProcessor: {
+		PermAccountsRead, PermTransactionsRead,
+		PermAccountsWrite, PermTransfersWrite,
+	},
+	RoleOperator: {
+		PermAccountsRead, PermTransactionsRead,
+		PermAccountsWrite, PermTransfersWrite,
+		PermAccountsAdmin,
+	},
+}
+
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Allowed reports whether role grants perm. Unknown roles grant nothing.
+func Allowed(role string, perm Permission) bool {
+	return slices.Contains(rolePermissions[role], perm)
+}
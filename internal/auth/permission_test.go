@@ -0,0 +1,29 @@
+package auth
+
+import "testing"
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		role    string
+		perm    Permission
+		allowed bool
+	}{
+		{RoleViewer, PermAccountsRead, true},
+		{RoleViewer, PermTransactionsRead, true},
+		{RoleViewer, PermTransfersWrite, false},
+		{RoleViewer, PermAccountsWrite, false},
+		{RoleProcessor, PermTransfersWrite, true},
+		{RoleProcessor, PermAccountsWrite, true},
+		{RoleProcessor, PermAccountsAdmin, false},
+		{RoleOperator, PermAccountsAdmin, true},
+		{"unknown", PermAccountsRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role+"/"+string(tt.perm), func(t *testing.T) {
+			if got := Allowed(tt.role, tt.perm); got != tt.allowed {
+				t.Errorf("Allowed(%s, %s) = %v, want %v", tt.role, tt.perm, got, tt.allowed)
+			}
+		})
+	}
+}
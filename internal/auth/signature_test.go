@@ -0,0 +1,38 @@
+package auth
+
+import "testing"
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"amount":100}`)
+	sig := Sign("new", "POST", "/api/v1/transactions", "1700000000", body)
+
+	tests := []struct {
+		name    string
+		secrets []string
+		method  string
+		uri     string
+		ts      string
+		body    string
+		valid   bool
+	}{
+		{"matching", []string{"new"}, "POST", "/api/v1/transactions", "1700000000", `{"amount":100}`, true},
+		{"rotated secret", []string{"old", "new"}, "POST", "/api/v1/transactions", "1700000000", `{"amount":100}`, true},
+		{"revoked secret", []string{"old"}, "POST", "/api/v1/transactions", "1700000000", `{"amount":100}`, false},
+		{"tampered body", []string{"new"}, "POST", "/api/v1/transactions", "1700000000", `{"amount":900}`, false},
+		{"other path", []string{"new"}, "POST", "/api/v1/journal", "1700000000", `{"amount":100}`, false},
+		{"other timestamp", []string{"new"}, "POST", "/api/v1/transactions", "1700000001", `{"amount":100}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VerifySignature(tt.secrets, sig, tt.method, tt.uri, tt.ts, []byte(tt.body))
+			if got != tt.valid {
+				t.Errorf("expected valid=%v, got %v", tt.valid, got)
+			}
+		})
+	}
+
+	if VerifySignature([]string{"new"}, "not-hex", "POST", "/", "1", nil) {
+		t.Error("expected malformed signature to be rejected")
+	}
+}
@@ -7,12 +7,16 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/ratelimit"
+	"github.com/gabrielvieirabra/payments-ledger/internal/telemetry"
 )
 
 type Config struct {
 	AppName         string
 	Environment     string
 	Port            int
+	GRPCPort        int
 	LogLevelStr     string
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
@@ -21,6 +25,32 @@ type Config struct {
 	MigrationsPath  string
 	WorkerPoolSize  int
 	WorkerQueueSize int
+
+	AuthEnabled      bool
+	SignatureEnabled bool
+	SignatureMaxSkew time.Duration
+
+	AuthorizationTTL            time.Duration
+	AuthorizationExpiryInterval time.Duration
+
+	IdempotencyStore          string
+	IdempotencyMemoryCapacity int
+	IdempotencyKeyTTL         time.Duration
+	IdempotencyLockTimeout    time.Duration
+	IdempotencyPurgeInterval  time.Duration
+	IdempotencyPurgeBatch     int
+
+	RateLimitEnabled       bool
+	RateLimitStore         string
+	RateLimitClient        ratelimit.Limit
+	RateLimitRoutes        map[string]ratelimit.Limit
+	RateLimitAccount       ratelimit.Limit
+	RateLimitPurgeInterval time.Duration
+	RateLimitPurgeBatch    int
+
+	TraceExporter    string
+	TraceFile        string
+	TraceSampleRatio float64
 }
 
 func Load() (*Config, error) {
@@ -28,11 +58,16 @@ func Load() (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid PORT: %w", err)
 	}
+	grpcPort, err := strconv.Atoi(getEnv("GRPC_PORT", "9090"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GRPC_PORT: %w", err)
+	}
 
 	cfg := &Config{
 		AppName:         getEnv("APP_NAME", "payments-ledger"),
 		Environment:     getEnv("ENVIRONMENT", "development"),
 		Port:            port,
+		GRPCPort:        grpcPort,
 		LogLevelStr:     getEnv("LOG_LEVEL", "info"),
 		ReadTimeout:     parseDuration("READ_TIMEOUT", "5s"),
 		WriteTimeout:    parseDuration("WRITE_TIMEOUT", "10s"),
@@ -41,6 +76,52 @@ func Load() (*Config, error) {
 		MigrationsPath:  getEnv("MIGRATIONS_PATH", "migrations"),
 		WorkerPoolSize:  parseInt("WORKER_POOL_SIZE", 10),
 		WorkerQueueSize: parseInt("WORKER_QUEUE_SIZE", 100),
+
+		AuthEnabled:      parseBool("AUTH_ENABLED", true),
+		SignatureEnabled: parseBool("SIGNATURE_ENABLED", false),
+		SignatureMaxSkew: parseDuration("SIGNATURE_MAX_SKEW", "5m"),
+
+		AuthorizationTTL:            parseDuration("AUTHORIZATION_TTL", "168h"),
+		AuthorizationExpiryInterval: parseDuration("AUTHORIZATION_EXPIRY_INTERVAL", "1m"),
+
+		IdempotencyStore:          strings.ToLower(getEnv("IDEMPOTENCY_STORE", "postgres")),
+		IdempotencyMemoryCapacity: parseInt("IDEMPOTENCY_MEMORY_CAPACITY", 10000),
+		IdempotencyKeyTTL:         parseDuration("IDEMPOTENCY_KEY_TTL", "24h"),
+		IdempotencyLockTimeout:    parseDuration("IDEMPOTENCY_LOCK_TIMEOUT", "30s"),
+		IdempotencyPurgeInterval:  parseDuration("IDEMPOTENCY_PURGE_INTERVAL", "5m"),
+		IdempotencyPurgeBatch:     parseInt("IDEMPOTENCY_PURGE_BATCH_SIZE", 1000),
+
+		RateLimitEnabled:       parseBool("RATE_LIMIT_ENABLED", false),
+		RateLimitStore:         strings.ToLower(getEnv("RATE_LIMIT_STORE", "memory")),
+		RateLimitPurgeInterval: parseDuration("RATE_LIMIT_PURGE_INTERVAL", "1m"),
+		RateLimitPurgeBatch:    parseInt("RATE_LIMIT_PURGE_BATCH_SIZE", 1000),
+
+		TraceExporter:    strings.ToLower(getEnv("TRACE_EXPORTER", "none")),
+		TraceFile:        getEnv("TRACE_FILE", "traces.jsonl"),
+		TraceSampleRatio: parseFloat("TRACE_SAMPLE_RATIO", 1),
+	}
+
+	if cfg.IdempotencyStore != "postgres" && cfg.IdempotencyStore != "memory" {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_STORE %q: must be postgres or memory", cfg.IdempotencyStore)
+	}
+
+	if cfg.RateLimitStore != ratelimit.StorePostgres && cfg.RateLimitStore != ratelimit.StoreMemory {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_STORE %q: must be postgres or memory", cfg.RateLimitStore)
+	}
+	if cfg.RateLimitClient, err = ratelimit.ParseLimit(getEnv("RATE_LIMIT_CLIENT", "100/1s")); err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_CLIENT: %w", err)
+	}
+	if cfg.RateLimitAccount, err = ratelimit.ParseLimit(getEnv("RATE_LIMIT_ACCOUNT", "20/1s")); err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_ACCOUNT: %w", err)
+	}
+	if cfg.RateLimitRoutes, err = ratelimit.ParseRoutes(getEnv("RATE_LIMIT_ROUTES", "")); err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_ROUTES: %w", err)
+	}
+
+	switch cfg.TraceExporter {
+	case telemetry.ExporterNone, telemetry.ExporterOTLP, telemetry.ExporterFile:
+	default:
+		return nil, fmt.Errorf("invalid TRACE_EXPORTER %q: must be none, otlp or file", cfg.TraceExporter)
 	}
 
 	return cfg, nil
@@ -71,6 +152,30 @@ func parseInt(envKey string, fallback int) int {
 	return v
 }
 
+func parseFloat(envKey string, fallback float64) float64 {
+	raw := getEnv(envKey, "")
+	if raw == "" {
+		return fallback
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return fallback
+	}
+	return v
+}
+
+func parseBool(envKey string, fallback bool) bool {
+	raw := getEnv(envKey, "")
+	if raw == "" {
+		return fallback
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return fallback
+	}
+	return v
+}
+
 func getEnv(key, fallback string) string {
 	if val, ok := os.LookupEnv(key); ok {
 		return val
@@ -3,6 +3,9 @@ package config
 import (
 	"log/slog"
 	"testing"
+	"time"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/ratelimit"
 )
 
 func TestLoad_Defaults(t *testing.T) {
@@ -20,6 +23,34 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.AppName != "payments-ledger" {
 		t.Errorf("expected app name payments-ledger, got %s", cfg.AppName)
 	}
+	if cfg.AuthorizationTTL != 168*time.Hour {
+		t.Errorf("expected authorization TTL 168h, got %s", cfg.AuthorizationTTL)
+	}
+	if !cfg.AuthEnabled {
+		t.Error("expected authentication to be enabled by default")
+	}
+	if cfg.IdempotencyStore != "postgres" {
+		t.Errorf("expected postgres idempotency store, got %s", cfg.IdempotencyStore)
+	}
+	if cfg.IdempotencyKeyTTL != 24*time.Hour {
+		t.Errorf("expected idempotency key TTL 24h, got %s", cfg.IdempotencyKeyTTL)
+	}
+	if cfg.IdempotencyLockTimeout != 30*time.Second {
+		t.Errorf("expected idempotency lock timeout 30s, got %s", cfg.IdempotencyLockTimeout)
+	}
+}
+
+func TestLoad_InvalidDurationFallsBack(t *testing.T) {
+	t.Setenv("AUTHORIZATION_TTL", "a week")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.AuthorizationTTL != 168*time.Hour {
+		t.Errorf("expected fallback authorization TTL 168h, got %s", cfg.AuthorizationTTL)
+	}
 }
 
 func TestLoad_CustomPort(t *testing.T) {
@@ -66,3 +97,54 @@ func TestConfig_LogLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_InvalidIdempotencyStore(t *testing.T) {
+	t.Setenv("IDEMPOTENCY_STORE", "redis")
+
+	if _, err := Load(); err == nil {
+		t.Error("expected error for unknown idempotency store")
+	}
+}
+
+func TestLoad_AuthDisabled(t *testing.T) {
+	t.Setenv("AUTH_ENABLED", "false")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.AuthEnabled {
+		t.Error("expected authentication to be disabled")
+	}
+}
+
+func TestLoad_RateLimits(t *testing.T) {
+	t.Setenv("RATE_LIMIT_CLIENT", "600/1m")
+	t.Setenv("RATE_LIMIT_ROUTES", "POST /api/v1/journal=5/1s")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.RateLimitEnabled {
+		t.Error("expected rate limiting to be off by default")
+	}
+	if cfg.RateLimitClient != (ratelimit.Limit{Requests: 600, Per: time.Minute}) {
+		t.Errorf("expected client limit 600/1m, got %s", cfg.RateLimitClient)
+	}
+	if cfg.RateLimitAccount != (ratelimit.Limit{Requests: 20, Per: time.Second}) {
+		t.Errorf("expected default account limit 20/1s, got %s", cfg.RateLimitAccount)
+	}
+	if cfg.RateLimitRoutes["POST /api/v1/journal"] != (ratelimit.Limit{Requests: 5, Per: time.Second}) {
+		t.Errorf("expected journal override 5/1s, got %v", cfg.RateLimitRoutes)
+	}
+}
+
+func TestLoad_InvalidRateLimit(t *testing.T) {
+	t.Setenv("RATE_LIMIT_ACCOUNT", "lots")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected invalid RATE_LIMIT_ACCOUNT to be rejected")
+	}
+}
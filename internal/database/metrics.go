@@ -0,0 +1,60 @@
+package database
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolCollector exports pgxpool statistics, read on every scrape.
+type PoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns    *prometheus.Desc
+	idleConns        *prometheus.Desc
+	totalConns       *prometheus.Desc
+	maxConns         *prometheus.Desc
+	acquires         *prometheus.Desc
+	emptyAcquires    *prometheus.Desc
+	canceledAcquires *prometheus.Desc
+	acquireDuration  *prometheus.Desc
+}
+
+func NewPoolCollector(pool *pgxpool.Pool) *PoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("ledger_db_pool_"+name, help, nil, nil)
+	}
+	return &PoolCollector{
+		pool:             pool,
+		acquiredConns:    desc("acquired_connections", "Connections currently checked out of the pool."),
+		idleConns:        desc("idle_connections", "Idle connections in the pool."),
+		totalConns:       desc("total_connections", "Open connections, including ones being established."),
+		maxConns:         desc("max_connections", "Maximum size of the pool."),
+		acquires:         desc("acquires_total", "Successful connection acquisitions."),
+		emptyAcquires:    desc("empty_acquires_total", "Acquisitions that had to wait because the pool was empty."),
+		canceledAcquires: desc("canceled_acquires_total", "Acquisitions cancelled by their context."),
+		acquireDuration:  desc("acquire_wait_seconds_total", "Total time spent waiting to acquire connections."),
+	}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.acquires
+	ch <- c.emptyAcquires
+	ch <- c.canceledAcquires
+	ch <- c.acquireDuration
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
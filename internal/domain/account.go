@@ -6,21 +6,68 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+// Account balances are tracked twice: Balance is the posted balance, the sum
+// of all entries, while AvailableBalance also subtracts pending authorization
+// holds and is what debits are checked against. AvailableBalance may go as low
+// as -OverdraftLimit, or without bound when UnlimitedOverdraft is set.
 type Account struct {
-	ID        uuid.UUID `json:"id"`
-	Owner     string    `json:"owner"`
-	Balance   int64     `json:"balance"`
-	Currency  string    `json:"currency"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                 uuid.UUID `json:"id"`
+	Owner              string    `json:"owner"`
+	Balance            int64     `json:"balance"`
+	AvailableBalance   int64     `json:"available_balance"`
+	OverdraftLimit     int64     `json:"overdraft_limit"`
+	UnlimitedOverdraft bool      `json:"unlimited_overdraft"`
+	AvailableCredit    *int64    `json:"available_credit"`
+	Currency           string    `json:"currency"`
+	Status             string    `json:"status"`
+	IsSystem           bool      `json:"is_system"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// CanDebit reports whether amount can be taken from the available balance
+// without exceeding the overdraft limit.
+func (a Account) CanDebit(amount int64) bool {
+	return a.UnlimitedOverdraft || a.AvailableBalance+a.OverdraftLimit >= amount
+}
+
+// AvailableCreditOf is the amount that can still be debited, or nil when the
+// account has no overdraft bound.
+func AvailableCreditOf(a Account) *int64 {
+	if a.UnlimitedOverdraft {
+		return nil
+	}
+	credit := a.AvailableBalance + a.OverdraftLimit
+	return &credit
 }
 
 type CreateAccountRequest struct {
-	Owner    string `json:"owner" binding:"required"`
-	Currency string `json:"currency" binding:"required,oneof=USD EUR BRL"`
+	Owner          string `json:"owner" binding:"required"`
+	Currency       string `json:"currency" binding:"required,oneof=USD EUR BRL"`
+	OverdraftLimit int64  `json:"overdraft_limit" binding:"min=0"`
+}
+
+// UpdateAccountLimitsRequest changes the overdraft settings of an account.
+// Omitted fields are left unchanged.
+type UpdateAccountLimitsRequest struct {
+	OverdraftLimit     *int64 `json:"overdraft_limit" binding:"omitempty,min=0"`
+	UnlimitedOverdraft *bool  `json:"unlimited_overdraft"`
+}
+
+type UpdateAccountStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active frozen closed"`
 }
 
+// ListAccountsParams pages through accounts. Offset is deprecated in favour
+// of Cursor and ignored when a cursor is given.
 type ListAccountsParams struct {
-	Limit  int32 `form:"limit,default=10" binding:"min=1,max=100"`
-	Offset int32 `form:"offset,default=0" binding:"min=0"`
+	Limit  int32  `form:"limit,default=10" binding:"min=1,max=100"`
+	Cursor string `form:"cursor"`
+	Offset int32  `form:"offset,default=0" binding:"min=0"`
 }
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	IntervalHour  = "hour"
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// BalanceParams selects the instant a balance is computed at. Entries posted
+// strictly before AsOf are included; a zero AsOf means now.
+type BalanceParams struct {
+	AsOf time.Time `form:"as_of"`
+}
+
+type Balance struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Currency  string    `json:"currency"`
+	Balance   int64     `json:"balance"`
+	AsOf      time.Time `json:"as_of"`
+}
+
+// BalanceCheck compares the stored account balance with the running balance of
+// its latest entry. An account without entries is consistent at zero.
+type BalanceCheck struct {
+	AccountID          uuid.UUID  `json:"account_id"`
+	Balance            int64      `json:"balance"`
+	LatestEntryID      *uuid.UUID `json:"latest_entry_id"`
+	LatestEntryBalance int64      `json:"latest_entry_balance"`
+	Consistent         bool       `json:"consistent"`
+}
+
+type BalanceHistoryParams struct {
+	From     time.Time `form:"from" binding:"required"`
+	To       time.Time `form:"to" binding:"required"`
+	Interval string    `form:"interval,default=day" binding:"oneof=hour day week month"`
+}
+
+// BalanceBucket summarizes the entries of an account over [Start, End).
+// Debits and credits are reported as positive totals.
+type BalanceBucket struct {
+	Start          time.Time `json:"start"`
+	End            time.Time `json:"end"`
+	OpeningBalance int64     `json:"opening_balance"`
+	TotalDebits    int64     `json:"total_debits"`
+	TotalCredits   int64     `json:"total_credits"`
+	ClosingBalance int64     `json:"closing_balance"`
+}
+
+type BalanceHistory struct {
+	AccountID uuid.UUID       `json:"account_id"`
+	Currency  string          `json:"currency"`
+	Interval  string          `json:"interval"`
+	Buckets   []BalanceBucket `json:"buckets"`
+}
+
+// TruncateToInterval returns the start of the UTC bucket containing t, using
+// the same boundaries as PostgreSQL's date_trunc (weeks start on Monday).
+func TruncateToInterval(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	switch interval {
+	case IntervalHour:
+		return t.Truncate(time.Hour)
+	case IntervalWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case IntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// NextInterval returns the start of the bucket following the one starting at t.
+func NextInterval(t time.Time, interval string) time.Time {
+	switch interval {
+	case IntervalHour:
+		return t.Add(time.Hour)
+	case IntervalWeek:
+		return t.AddDate(0, 0, 7)
+	case IntervalMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTruncateToInterval(t *testing.T) {
+	// Wednesday
+	ts := time.Date(2025, 3, 12, 15, 42, 7, 0, time.UTC)
+
+	tests := []struct {
+		interval string
+		expected time.Time
+	}{
+		{IntervalHour, time.Date(2025, 3, 12, 15, 0, 0, 0, time.UTC)},
+		{IntervalDay, time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)},
+		{IntervalWeek, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{IntervalMonth, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.interval, func(t *testing.T) {
+			if got := TruncateToInterval(ts, tt.interval); !got.Equal(tt.expected) {
+				t.Errorf("TruncateToInterval(%s) = %s, want %s", tt.interval, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTruncateToInterval_WeekStartsOnMonday(t *testing.T) {
+	sunday := time.Date(2025, 3, 16, 23, 0, 0, 0, time.UTC)
+	monday := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	if got := TruncateToInterval(sunday, IntervalWeek); !got.Equal(monday) {
+		t.Errorf("expected %s, got %s", monday, got)
+	}
+	if got := TruncateToInterval(monday, IntervalWeek); !got.Equal(monday) {
+		t.Errorf("expected Monday to be its own week start, got %s", got)
+	}
+}
+
+func TestNextInterval_Month(t *testing.T) {
+	jan := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	if got := NextInterval(jan, IntervalMonth); !got.Equal(feb) {
+		t.Errorf("expected %s, got %s", feb, got)
+	}
+}
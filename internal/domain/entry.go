@@ -6,20 +6,29 @@ import (
 	"github.com/google/uuid"
 )
 
+// Entry is a posting on one account. TransactionID is nil only for historical
+// entries that migration 000007 could not link to exactly one transaction.
 type Entry struct {
-	ID        uuid.UUID `json:"id"`
-	AccountID uuid.UUID `json:"account_id"`
-	Amount    int64     `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            uuid.UUID  `json:"id"`
+	AccountID     uuid.UUID  `json:"account_id"`
+	TransactionID *uuid.UUID `json:"transaction_id"`
+	Amount        int64      `json:"amount"`
+	BalanceAfter  int64      `json:"balance_after"`
+	CreatedAt     time.Time  `json:"created_at"`
 }
 
 type CreateEntryParams struct {
-	AccountID uuid.UUID `json:"account_id"`
-	Amount    int64     `json:"amount"`
+	AccountID     uuid.UUID `json:"account_id"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+	Amount        int64     `json:"amount"`
+	BalanceAfter  int64     `json:"balance_after"`
 }
 
+// ListEntriesParams pages through the entries of an account. Offset is
+// deprecated in favour of Cursor and ignored when a cursor is given.
 type ListEntriesParams struct {
-	AccountID uuid.UUID `form:"account_id" binding:"required"`
+	AccountID uuid.UUID `form:"-"`
 	Limit     int32     `form:"limit,default=10" binding:"min=1,max=100"`
+	Cursor    string    `form:"cursor"`
 	Offset    int32     `form:"offset,default=0" binding:"min=0"`
 }
@@ -6,13 +6,25 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	IdempotencyStatusProcessing = "processing"
+	IdempotencyStatusCompleted  = "completed"
+)
+
+// IdempotencyKey is a stored response, or a reservation while the request
+// that claimed the key is still running. StatusCode and ResponseBody are only
+// set once the key is completed.
 type IdempotencyKey struct {
-	ID             uuid.UUID `json:"id"`
-	IdempotencyKey string    `json:"idempotency_key"`
-	Method         string    `json:"method"`
-	Path           string    `json:"path"`
-	StatusCode     int       `json:"status_code"`
-	ResponseBody   []byte    `json:"response_body"`
-	CreatedAt      time.Time `json:"created_at"`
-	ExpiresAt      time.Time `json:"expires_at"`
+	ID             uuid.UUID  `json:"id"`
+	ClientID       *uuid.UUID `json:"client_id"`
+	IdempotencyKey string     `json:"idempotency_key"`
+	Method         string     `json:"method"`
+	Path           string     `json:"path"`
+	RequestHash    string     `json:"request_hash"`
+	Status         string     `json:"status"`
+	StatusCode     int        `json:"status_code"`
+	ResponseBody   []byte     `json:"response_body"`
+	LockedAt       *time.Time `json:"locked_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
 }
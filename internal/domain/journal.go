@@ -0,0 +1,21 @@
+package domain
+
+import "github.com/google/uuid"
+
+// JournalLeg is a single posting of a journal transaction. Positive amounts
+// credit the account and negative amounts debit it.
+type JournalLeg struct {
+	AccountID uuid.UUID `json:"account_id" binding:"required"`
+	Amount    int64     `json:"amount" binding:"required"`
+	Currency  string    `json:"currency" binding:"required,oneof=USD EUR BRL"`
+}
+
+type CreateJournalRequest struct {
+	Legs []JournalLeg `json:"legs" binding:"required,min=2,max=100,dive"`
+}
+
+type JournalResult struct {
+	Transaction Transaction `json:"transaction"`
+	Entries     []Entry     `json:"entries"`
+	Accounts    []Account   `json:"accounts"`
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the keyset position of the last row of a page. List endpoints are
+// ordered by (created_at, id) descending and resume strictly after it.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Encode returns the opaque form of the cursor handed out to clients.
+func (c Cursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return Cursor{CreatedAt: createdAt, ID: parsedID}, nil
+}
+
+func (a Account) Cursor() Cursor     { return Cursor{CreatedAt: a.CreatedAt, ID: a.ID} }
+func (e Entry) Cursor() Cursor       { return Cursor{CreatedAt: e.CreatedAt, ID: e.ID} }
+func (t Transaction) Cursor() Cursor { return Cursor{CreatedAt: t.CreatedAt, ID: t.ID} }
+
+// Page is the response envelope of list endpoints. NextCursor is empty on the
+// last page.
+type Page[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NewPage builds a page from rows fetched with limit+1, using the extra row
+// only to tell whether another page exists.
+func NewPage[T any](rows []T, limit int32, cursorOf func(T) Cursor) Page[T] {
+	if rows == nil {
+		rows = []T{}
+	}
+	if int32(len(rows)) <= limit {
+		return Page[T]{Data: rows}
+	}
+
+	rows = rows[:limit]
+	return Page[T]{
+		Data:       rows,
+		NextCursor: cursorOf(rows[len(rows)-1]).Encode(),
+	}
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	want := Cursor{
+		CreatedAt: time.Date(2025, 3, 14, 15, 9, 26, 535897000, time.UTC),
+		ID:        uuid.New(),
+	}
+
+	got, err := DecodeCursor(want.Encode())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("DecodeCursor(Encode()) = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	tests := []string{
+		"not base64!",
+		"bm8tc2VwYXJhdG9y",                   // "no-separator"
+		"bm90LWEtdGltZXxub3QtYS11dWlk",       // "not-a-time|not-a-uuid"
+		"MjAyNS0wMy0xNFQxNTowOToyNlp8bm9wZQ", // "2025-03-14T15:09:26Z|nope"
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			if _, err := DecodeCursor(input); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("DecodeCursor(%q) error = %v, want %v", input, err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []Entry{
+		{ID: uuid.New(), CreatedAt: base.Add(2 * time.Second)},
+		{ID: uuid.New(), CreatedAt: base.Add(time.Second)},
+		{ID: uuid.New(), CreatedAt: base},
+	}
+	cursorOf := Entry.Cursor
+
+	t.Run("more rows than limit", func(t *testing.T) {
+		page := NewPage(rows, 2, cursorOf)
+		if len(page.Data) != 2 {
+			t.Fatalf("expected 2 rows, got %d", len(page.Data))
+		}
+		next, err := DecodeCursor(page.NextCursor)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if next.ID != rows[1].ID {
+			t.Errorf("expected cursor at row 1, got %s", next.ID)
+		}
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		page := NewPage(rows, 3, cursorOf)
+		if len(page.Data) != 3 || page.NextCursor != "" {
+			t.Errorf("expected 3 rows without cursor, got %d rows and %q", len(page.Data), page.NextCursor)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		page := NewPage[Entry](nil, 10, cursorOf)
+		if page.Data == nil || len(page.Data) != 0 {
+			t.Errorf("expected empty non-nil data, got %#v", page.Data)
+		}
+	})
+}
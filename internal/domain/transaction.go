@@ -6,25 +6,112 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	TransactionKindTransfer   = "transfer"
+	TransactionKindDeposit    = "deposit"
+	TransactionKindWithdrawal = "withdrawal"
+	TransactionKindJournal    = "journal"
+	TransactionKindReversal   = "reversal"
+)
+
+const (
+	TransactionStatusPending = "pending"
+	TransactionStatusPosted  = "posted"
+	TransactionStatusVoided  = "voided"
+	TransactionStatusExpired = "expired"
+)
+
+const (
+	TransferModeImmediate = "immediate"
+	TransferModeAuthorize = "authorize"
+)
+
+const (
+	ReversalStatusNone    = "none"
+	ReversalStatusPartial = "partial"
+	ReversalStatusFull    = "full"
+)
+
+// Transaction is the header of a posting. FromAccountID and ToAccountID are
+// only set for two-legged transactions; journal legs live in entries.
 type Transaction struct {
-	ID            uuid.UUID `json:"id"`
-	FromAccountID uuid.UUID `json:"from_account_id"`
-	ToAccountID   uuid.UUID `json:"to_account_id"`
-	Amount        int64     `json:"amount"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID             uuid.UUID  `json:"id"`
+	FromAccountID  *uuid.UUID `json:"from_account_id"`
+	ToAccountID    *uuid.UUID `json:"to_account_id"`
+	Amount         int64      `json:"amount"`
+	Kind           string     `json:"kind"`
+	Status         string     `json:"status"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	ReversalOf     *uuid.UUID `json:"reversal_of,omitempty"`
+	ReversedAmount int64      `json:"reversed_amount"`
+	ReversalStatus string     `json:"reversal_status"`
+	ClientID       *uuid.UUID `json:"client_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// RemainingAmount is the part of the transaction that can still be reversed.
+func (t Transaction) RemainingAmount() int64 {
+	return t.Amount - t.ReversedAmount
 }
 
+func ReversalStatusOf(amount, reversed int64) string {
+	switch {
+	case reversed <= 0:
+		return ReversalStatusNone
+	case reversed < amount:
+		return ReversalStatusPartial
+	default:
+		return ReversalStatusFull
+	}
+}
+
+// CreateTransactionRequest moves money between two accounts. With mode
+// "authorize" the funds are only held until the transfer is captured or voided.
 type CreateTransactionRequest struct {
 	FromAccountID uuid.UUID `json:"from_account_id" binding:"required"`
 	ToAccountID   uuid.UUID `json:"to_account_id" binding:"required"`
 	Amount        int64     `json:"amount" binding:"required,gt=0"`
 	Currency      string    `json:"currency" binding:"required,oneof=USD EUR BRL"`
+	Mode          string    `json:"mode" binding:"omitempty,oneof=immediate authorize"`
+}
+
+// CreateFundingRequest is the payload for deposits and withdrawals. The
+// counterparty is always the system funding account of the given currency.
+type CreateFundingRequest struct {
+	Amount   int64  `json:"amount" binding:"required,gt=0"`
+	Currency string `json:"currency" binding:"required,oneof=USD EUR BRL"`
+}
+
+// CreateReversalRequest refunds part of a transaction. A zero amount reverses
+// whatever has not been reversed yet.
+type CreateReversalRequest struct {
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// ListTransactionsParams pages through the transactions of an account. Offset
+// is deprecated in favour of Cursor and ignored when a cursor is given.
+type ListTransactionsParams struct {
+	AccountID uuid.UUID `form:"-"`
+	Limit     int32     `form:"limit,default=10" binding:"min=1,max=100"`
+	Cursor    string    `form:"cursor"`
+	Offset    int32     `form:"offset,default=0" binding:"min=0"`
+}
+
+type CreateTransactionParams struct {
+	FromAccountID *uuid.UUID
+	ToAccountID   *uuid.UUID
+	Amount        int64
+	Kind          string
+	Status        string
+	ExpiresAt     *time.Time
+	ReversalOf    *uuid.UUID
+	ClientID      *uuid.UUID
 }
 
 type TransactionResult struct {
 	Transaction Transaction `json:"transaction"`
 	FromAccount Account     `json:"from_account"`
 	ToAccount   Account     `json:"to_account"`
-	FromEntry   Entry       `json:"from_entry"`
-	ToEntry     Entry       `json:"to_entry"`
+	FromEntry   *Entry      `json:"from_entry,omitempty"`
+	ToEntry     *Entry      `json:"to_entry,omitempty"`
 }
@@ -1,14 +1,13 @@
 package handler
 
 import (
-	"errors"
-	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 
+	"github.com/gabrielvieirabra/payments-ledger/internal/auth"
 	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
 	"github.com/gabrielvieirabra/payments-ledger/internal/service"
 )
 
@@ -23,14 +22,23 @@ func NewAccountHandler(svc *service.AccountService) *AccountHandler {
 func (h *AccountHandler) Create(c *gin.Context) {
 	var req domain.CreateAccountRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Validation(c, err)
 		return
 	}
+	// Limits are otherwise changed only through PATCH /:id/limits, which needs
+	// accounts:admin, so granting one at creation needs it too
+	if req.OverdraftLimit > 0 {
+		if client, ok := auth.ClientFromContext(c.Request.Context()); ok && !auth.Allowed(client.Role, auth.PermAccountsAdmin) {
+			problem.Abort(c, problem.New(http.StatusForbidden, problem.CodePermissionDenied, "permission denied").
+				With("permission", auth.PermAccountsAdmin).
+				With("role", client.Role))
+			return
+		}
+	}
 
 	acc, err := h.svc.Create(c.Request.Context(), req)
 	if err != nil {
-		slog.Error("failed to create account", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create account"})
+		problem.Respond(c, err, "failed to create account")
 		return
 	}
 
@@ -38,20 +46,14 @@ func (h *AccountHandler) Create(c *gin.Context) {
 }
 
 func (h *AccountHandler) GetByID(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+	id, ok := parseID(c, "account")
+	if !ok {
 		return
 	}
 
 	acc, err := h.svc.GetByID(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, service.ErrAccountNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
-			return
-		}
-		slog.Error("failed to get account", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get account"})
+		problem.Respond(c, err, "failed to get account")
 		return
 	}
 
@@ -61,38 +63,69 @@ func (h *AccountHandler) GetByID(c *gin.Context) {
 func (h *AccountHandler) List(c *gin.Context) {
 	var params domain.ListAccountsParams
 	if err := c.ShouldBindQuery(&params); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Validation(c, err)
 		return
 	}
 
 	accounts, err := h.svc.List(c.Request.Context(), params)
 	if err != nil {
-		slog.Error("failed to list accounts", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list accounts"})
+		problem.Respond(c, err, "failed to list accounts")
 		return
 	}
 
 	c.JSON(http.StatusOK, accounts)
 }
 
-func (h *AccountHandler) Delete(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+func (h *AccountHandler) UpdateStatus(c *gin.Context) {
+	id, ok := parseID(c, "account")
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateAccountStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Validation(c, err)
+		return
+	}
+
+	acc, err := h.svc.UpdateStatus(c.Request.Context(), id, req.Status)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		problem.Respond(c, err, "failed to update account status")
+		return
+	}
+
+	c.JSON(http.StatusOK, acc)
+}
+
+func (h *AccountHandler) UpdateLimits(c *gin.Context) {
+	id, ok := parseID(c, "account")
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateAccountLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Validation(c, err)
+		return
+	}
+
+	acc, err := h.svc.UpdateLimits(c.Request.Context(), id, req)
+	if err != nil {
+		problem.Respond(c, err, "failed to update account limits")
+		return
+	}
+
+	c.JSON(http.StatusOK, acc)
+}
+
+func (h *AccountHandler) Delete(c *gin.Context) {
+	id, ok := parseID(c, "account")
+	if !ok {
 		return
 	}
 
 	if err := h.svc.Delete(c.Request.Context(), id); err != nil {
-		if errors.Is(err, service.ErrAccountNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
-			return
-		}
-		if errors.Is(err, service.ErrAccountHasReferences) {
-			c.JSON(http.StatusConflict, gin.H{"error": "account has existing entries or transactions"})
-			return
-		}
-		slog.Error("failed to delete account", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete account"})
+		problem.Respond(c, err, "failed to delete account")
 		return
 	}
 
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
+	"github.com/gabrielvieirabra/payments-ledger/internal/service"
+)
+
+type BalanceHandler struct {
+	svc *service.BalanceService
+}
+
+func NewBalanceHandler(svc *service.BalanceService) *BalanceHandler {
+	return &BalanceHandler{svc: svc}
+}
+
+func (h *BalanceHandler) AsOf(c *gin.Context) {
+	accountID, ok := parseID(c, "account")
+	if !ok {
+		return
+	}
+
+	var params domain.BalanceParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		problem.Validation(c, err)
+		return
+	}
+
+	balance, err := h.svc.AsOf(c.Request.Context(), accountID, params)
+	if err != nil {
+		problem.Respond(c, err, "failed to get balance")
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+func (h *BalanceHandler) History(c *gin.Context) {
+	accountID, ok := parseID(c, "account")
+	if !ok {
+		return
+	}
+
+	var params domain.BalanceHistoryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		problem.Validation(c, err)
+		return
+	}
+
+	history, err := h.svc.History(c.Request.Context(), accountID, params)
+	if err != nil {
+		problem.Respond(c, err, "failed to get balance history")
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func (h *BalanceHandler) Verify(c *gin.Context) {
+	accountID, ok := parseID(c, "account")
+	if !ok {
+		return
+	}
+
+	check, err := h.svc.Verify(c.Request.Context(), accountID)
+	if err != nil {
+		problem.Respond(c, err, "failed to verify balance")
+		return
+	}
+
+	c.JSON(http.StatusOK, check)
+}
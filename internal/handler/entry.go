@@ -1,14 +1,12 @@
 package handler
 
 import (
-	"errors"
-	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 
 	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
 	"github.com/gabrielvieirabra/payments-ledger/internal/service"
 )
 
@@ -21,20 +19,14 @@ func NewEntryHandler(svc *service.EntryService) *EntryHandler {
 }
 
 func (h *EntryHandler) GetByID(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entry id"})
+	id, ok := parseID(c, "entry")
+	if !ok {
 		return
 	}
 
 	entry, err := h.svc.GetByID(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, service.ErrEntryNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "entry not found"})
-			return
-		}
-		slog.Error("failed to get entry", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get entry"})
+		problem.Respond(c, err, "failed to get entry")
 		return
 	}
 
@@ -42,23 +34,21 @@ func (h *EntryHandler) GetByID(c *gin.Context) {
 }
 
 func (h *EntryHandler) ListByAccount(c *gin.Context) {
-	accountID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+	accountID, ok := parseID(c, "account")
+	if !ok {
 		return
 	}
 
 	var params domain.ListEntriesParams
 	if err := c.ShouldBindQuery(&params); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Validation(c, err)
 		return
 	}
 	params.AccountID = accountID
 
 	entries, err := h.svc.ListByAccount(c.Request.Context(), params)
 	if err != nil {
-		slog.Error("failed to list entries", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list entries"})
+		problem.Respond(c, err, "failed to list entries")
 		return
 	}
 
@@ -5,6 +5,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
 )
 
 type HealthHandler struct {
@@ -21,10 +23,8 @@ func (h *HealthHandler) Liveness(c *gin.Context) {
 
 func (h *HealthHandler) Readiness(c *gin.Context) {
 	if err := h.pool.Ping(c.Request.Context()); err != nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"status": "unavailable",
-			"error":  "database connection failed",
-		})
+		problem.Abort(c, problem.New(http.StatusServiceUnavailable, problem.CodeUnavailable, "database connection failed").
+			With("status", "unavailable"))
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"status": "ready"})
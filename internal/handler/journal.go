@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/idempotency"
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
+	"github.com/gabrielvieirabra/payments-ledger/internal/service"
+)
+
+type JournalHandler struct {
+	svc *service.JournalService
+}
+
+func NewJournalHandler(svc *service.JournalService) *JournalHandler {
+	return &JournalHandler{svc: svc}
+}
+
+func (h *JournalHandler) Create(c *gin.Context) {
+	var req domain.CreateJournalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Validation(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	idempotency.Expect(ctx, http.StatusCreated)
+	result, err := h.svc.Post(ctx, req)
+	if err != nil {
+		problem.Respond(c, err, "failed to post journal")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
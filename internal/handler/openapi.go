@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/openapi"
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
+)
+
+// OpenAPIPath serves the API description. It is public, like the health
+// checks, so that clients can be generated without a key.
+const OpenAPIPath = "/api/v1/openapi.json"
+
+// apiRoutes documents every route of NewRouter. TestOpenAPI_DocumentsEveryRoute
+// fails when the two drift apart.
+var apiRoutes = []openapi.Route{
+	{Method: http.MethodGet, Path: "/healthz", OperationID: "liveness", Summary: "Liveness check", Tag: "health",
+		Status: http.StatusOK, Response: map[string]string{}, Public: true},
+	{Method: http.MethodGet, Path: "/readyz", OperationID: "readiness", Summary: "Readiness check, pings the database", Tag: "health",
+		Status: http.StatusOK, Response: map[string]string{}, Errors: []int{http.StatusServiceUnavailable}, Public: true},
+	{Method: http.MethodGet, Path: "/metrics", OperationID: "metrics", Summary: "Prometheus metrics", Tag: "health",
+		Status: http.StatusOK, Response: "", ResponseType: "text/plain", Public: true},
+	{Method: http.MethodGet, Path: OpenAPIPath, OperationID: "openapi", Summary: "This document", Tag: "health",
+		Status: http.StatusOK, Response: map[string]any{}, Public: true},
+
+	{Method: http.MethodPost, Path: "/api/v1/accounts", OperationID: "createAccount", Summary: "Create an account", Tag: "accounts",
+		Body: domain.CreateAccountRequest{}, Status: http.StatusCreated, Response: domain.Account{}, Idempotent: true},
+	{Method: http.MethodGet, Path: "/api/v1/accounts", OperationID: "listAccounts", Summary: "List accounts", Tag: "accounts",
+		Params: domain.ListAccountsParams{}, Status: http.StatusOK, Response: domain.Page[domain.Account]{}},
+	{Method: http.MethodGet, Path: "/api/v1/accounts/:id", OperationID: "getAccount", Summary: "Get an account", Tag: "accounts",
+		Status: http.StatusOK, Response: domain.Account{}, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodDelete, Path: "/api/v1/accounts/:id", OperationID: "deleteAccount", Summary: "Delete an account that was never used", Tag: "accounts",
+		Status: http.StatusNoContent, Errors: []int{http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity}},
+	{Method: http.MethodPatch, Path: "/api/v1/accounts/:id/status", OperationID: "updateAccountStatus", Summary: "Freeze, unfreeze or close an account", Tag: "accounts",
+		Body: domain.UpdateAccountStatusRequest{}, Status: http.StatusOK, Response: domain.Account{},
+		Errors: []int{http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity}},
+	{Method: http.MethodPatch, Path: "/api/v1/accounts/:id/limits", OperationID: "updateAccountLimits", Summary: "Change the overdraft settings of an account", Tag: "accounts",
+		Body: domain.UpdateAccountLimitsRequest{}, Status: http.StatusOK, Response: domain.Account{},
+		Errors: []int{http.StatusNotFound, http.StatusUnprocessableEntity}},
+	{Method: http.MethodGet, Path: "/api/v1/accounts/:id/balance", OperationID: "getBalance", Summary: "Balance at a point in time", Tag: "balances",
+		Params: domain.BalanceParams{}, Status: http.StatusOK, Response: domain.Balance{}, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodGet, Path: "/api/v1/accounts/:id/balance/verify", OperationID: "verifyBalance", Summary: "Compare the balance with the latest entry", Tag: "balances",
+		Status: http.StatusOK, Response: domain.BalanceCheck{}, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodGet, Path: "/api/v1/accounts/:id/balance-history", OperationID: "getBalanceHistory", Summary: "Balance movements per interval", Tag: "balances",
+		Params: domain.BalanceHistoryParams{}, Status: http.StatusOK, Response: domain.BalanceHistory{}, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodGet, Path: "/api/v1/accounts/:id/entries", OperationID: "listAccountEntries", Summary: "List the entries of an account", Tag: "entries",
+		Params: domain.ListEntriesParams{}, Status: http.StatusOK, Response: domain.Page[domain.Entry]{}},
+	{Method: http.MethodGet, Path: "/api/v1/accounts/:id/transactions", OperationID: "listAccountTransactions", Summary: "List the transactions of an account", Tag: "transactions",
+		Params: domain.ListTransactionsParams{}, Status: http.StatusOK, Response: domain.Page[domain.Transaction]{}},
+	{Method: http.MethodPost, Path: "/api/v1/accounts/:id/deposits", OperationID: "deposit", Summary: "Deposit money from outside the ledger", Tag: "transactions",
+		Body: domain.CreateFundingRequest{}, Status: http.StatusCreated, Response: domain.TransactionResult{}, Idempotent: true,
+		Errors: []int{http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusLocked}},
+	{Method: http.MethodPost, Path: "/api/v1/accounts/:id/withdrawals", OperationID: "withdraw", Summary: "Withdraw money out of the ledger", Tag: "transactions",
+		Body: domain.CreateFundingRequest{}, Status: http.StatusCreated, Response: domain.TransactionResult{}, Idempotent: true,
+		Errors: []int{http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusLocked}},
+
+	{Method: http.MethodGet, Path: "/api/v1/entries/:id", OperationID: "getEntry", Summary: "Get an entry", Tag: "entries",
+		Status: http.StatusOK, Response: domain.Entry{}, Errors: []int{http.StatusNotFound}},
+
+	{Method: http.MethodPost, Path: "/api/v1/transactions", OperationID: "transfer", Summary: "Transfer between accounts, or authorize a hold", Tag: "transactions",
+		Body: domain.CreateTransactionRequest{}, Status: http.StatusCreated, Response: domain.TransactionResult{}, Idempotent: true,
+		Errors: []int{http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusLocked}},
+	{Method: http.MethodGet, Path: "/api/v1/transactions/:id", OperationID: "getTransaction", Summary: "Get a transaction", Tag: "transactions",
+		Status: http.StatusOK, Response: domain.Transaction{}, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodGet, Path: "/api/v1/transactions/:id/entries", OperationID: "listTransactionEntries", Summary: "List the entries of a transaction", Tag: "transactions",
+		Status: http.StatusOK, Response: []domain.Entry{}, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodPost, Path: "/api/v1/transactions/:id/reversals", OperationID: "reverseTransaction", Summary: "Refund all or part of a transaction", Tag: "transactions",
+		Description: "Without a body, the amount not yet reversed is refunded.",
+		Body:        domain.CreateReversalRequest{}, BodyOptional: true, Status: http.StatusCreated, Response: domain.TransactionResult{}, Idempotent: true,
+		Errors: []int{http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusLocked}},
+	{Method: http.MethodPost, Path: "/api/v1/transactions/:id/capture", OperationID: "captureTransaction", Summary: "Settle an authorization", Tag: "transactions",
+		Status: http.StatusOK, Response: domain.TransactionResult{}, Idempotent: true,
+		Errors: []int{http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusLocked}},
+	{Method: http.MethodPost, Path: "/api/v1/transactions/:id/void", OperationID: "voidTransaction", Summary: "Cancel an authorization and release its hold", Tag: "transactions",
+		Status: http.StatusOK, Response: domain.TransactionResult{}, Idempotent: true,
+		Errors: []int{http.StatusNotFound, http.StatusConflict}},
+
+	{Method: http.MethodPost, Path: "/api/v1/journal", OperationID: "postJournal", Summary: "Post a balanced multi-leg transaction", Tag: "journal",
+		Body: domain.CreateJournalRequest{}, Status: http.StatusCreated, Response: domain.JournalResult{}, Idempotent: true,
+		Errors: []int{http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusLocked}},
+}
+
+// OpenAPI describes the HTTP API.
+func OpenAPI() *openapi.Document {
+	return openapi.Build(openapi.Spec{
+		Info: openapi.Info{
+			Title:       "payments-ledger",
+			Description: "Double-entry payments ledger. Amounts are integers in the currency's minor unit.",
+			Version:     "1.0.0",
+		},
+		SecuritySchemes: map[string]openapi.SecurityScheme{
+			"apiKey": {Type: "http", Scheme: "bearer", Description: "API key created with the apiclient tool"},
+		},
+		Security:    []openapi.SecurityRequirement{{"apiKey": {}}},
+		Problem:     problem.Problem{},
+		ProblemType: problem.ContentType,
+		Routes:      apiRoutes,
+	})
+}
+
+func serveOpenAPI(doc *openapi.Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestOpenAPI_DocumentsEveryRoute(t *testing.T) {
+	router := newTestRouter(t)
+	doc := OpenAPI()
+
+	registered := make(map[string]bool)
+	for _, r := range router.Routes() {
+		registered[r.Method+" "+r.Path] = true
+		if _, ok := doc.Operation(r.Method, r.Path); !ok {
+			t.Errorf("route %s %s is not documented in apiRoutes", r.Method, r.Path)
+		}
+	}
+
+	ids := make(map[string]bool)
+	for _, r := range apiRoutes {
+		if !registered[r.Method+" "+r.Path] {
+			t.Errorf("apiRoutes documents %s %s, which is not registered", r.Method, r.Path)
+		}
+		if ids[r.OperationID] {
+			t.Errorf("operation id %s is used twice", r.OperationID)
+		}
+		ids[r.OperationID] = true
+	}
+}
+
+func TestOpenAPI_Served(t *testing.T) {
+	router := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, OpenAPIPath, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the document to be public, got %d", w.Code)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["openapi"] != "3.0.3" {
+		t.Errorf("expected an OpenAPI 3 document, got %v", body["openapi"])
+	}
+}
+
+func TestOpenAPI_CheckedInCopyIsCurrent(t *testing.T) {
+	want, err := os.ReadFile("../../api/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got bytes.Buffer
+	enc := json.NewEncoder(&got)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(OpenAPI()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Error("api/openapi.json is out of date, run: make openapi")
+	}
+}
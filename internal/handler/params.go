@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
+)
+
+// parseID parses the :id route parameter, answering 400 when it is not a
+// UUID. resource names the parameter in the error, e.g. "account".
+func parseID(c *gin.Context, resource string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeInvalidID, "invalid "+resource+" id"))
+		return uuid.Nil, false
+	}
+	return id, true
+}
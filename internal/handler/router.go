@@ -3,61 +3,131 @@ package handler
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"github.com/gabrielvieirabra/payments-ledger/internal/auth"
+	"github.com/gabrielvieirabra/payments-ledger/internal/config"
+	"github.com/gabrielvieirabra/payments-ledger/internal/idempotency"
 	"github.com/gabrielvieirabra/payments-ledger/internal/middleware"
-	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
+	"github.com/gabrielvieirabra/payments-ledger/internal/ratelimit"
 	"github.com/gabrielvieirabra/payments-ledger/internal/service"
-	"github.com/gabrielvieirabra/payments-ledger/internal/worker"
 )
 
-func NewRouter(pool *pgxpool.Pool, wp *worker.Pool) *gin.Engine {
+// Services bundles the business services exposed over HTTP. They are built
+// in main so that background jobs can share them.
+type Services struct {
+	Accounts     *service.AccountService
+	Entries      *service.EntryService
+	Transactions *service.TransactionService
+	Journal      *service.JournalService
+	Balances     *service.BalanceService
+}
+
+// Stores bundles the backends used by the router's middleware.
+type Stores struct {
+	Idempotency idempotency.Store
+	Clients     middleware.ClientResolver
+	Secrets     middleware.SecretResolver
+	RateLimits  ratelimit.Store
+}
+
+func NewRouter(pool *pgxpool.Pool, svcs Services, stores Stores, cfg *config.Config) *gin.Engine {
 	router := gin.New()
 	_ = router.SetTrustedProxies(nil)
-	router.Use(gin.Recovery())
-	router.Use(gin.Logger())
+	// Recovery runs inside the access log, tracing and metrics so that they
+	// record panics as 500s
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Tracing())
+	router.Use(middleware.AccessLog())
+	router.Use(middleware.Metrics())
+	router.Use(middleware.Recovery())
 	router.Use(middleware.BodySizeLimit())
 
-	accountRepo := repository.NewAccountRepository(pool)
-	entryRepo := repository.NewEntryRepository(pool)
-	transactionRepo := repository.NewTransactionRepository(pool)
-	idempotencyRepo := repository.NewIdempotencyRepository(pool)
+	idempotencyMw := middleware.Idempotency(stores.Idempotency, cfg.IdempotencyLockTimeout)
 
-	accountSvc := service.NewAccountService(accountRepo)
-	entrySvc := service.NewEntryService(entryRepo)
-	transactionSvc := service.NewTransactionService(accountRepo, entryRepo, transactionRepo, wp)
+	// Permissions are checked before idempotency so that forbidden requests
+	// never reserve a key
+	require := func(perm auth.Permission) gin.HandlerFunc {
+		if !cfg.AuthEnabled {
+			return func(c *gin.Context) { c.Next() }
+		}
+		return middleware.RequirePermission(perm)
+	}
+	accountsRead := require(auth.PermAccountsRead)
+	accountsWrite := require(auth.PermAccountsWrite)
+	accountsAdmin := require(auth.PermAccountsAdmin)
+	transfersWrite := require(auth.PermTransfersWrite)
+	transactionsRead := require(auth.PermTransactionsRead)
 
-	idempotencyMw := middleware.Idempotency(idempotencyRepo)
+	// Accounts are limited after permissions, so that rejected callers cannot
+	// drain another client's account bucket. Only debits are limited:
+	// deposits only credit the account, and reversals, captures and voids
+	// name a transaction, not an account, and only move money that an
+	// already limited posting moved
+	accountLimit := func(c *gin.Context) { c.Next() }
+	if cfg.RateLimitEnabled {
+		accountLimit = middleware.RateLimitAccounts(stores.RateLimits, cfg.RateLimitAccount)
+	}
 
 	healthH := NewHealthHandler(pool)
-	accountH := NewAccountHandler(accountSvc)
-	entryH := NewEntryHandler(entrySvc)
-	transactionH := NewTransactionHandler(transactionSvc)
+	accountH := NewAccountHandler(svcs.Accounts)
+	entryH := NewEntryHandler(svcs.Entries)
+	transactionH := NewTransactionHandler(svcs.Transactions)
+	journalH := NewJournalHandler(svcs.Journal)
+	balanceH := NewBalanceHandler(svcs.Balances)
 
 	router.GET("/healthz", healthH.Liveness)
 	router.GET("/readyz", healthH.Readiness)
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	router.GET(OpenAPIPath, serveOpenAPI(OpenAPI()))
 
 	v1 := router.Group("/api/v1")
+	if cfg.AuthEnabled {
+		if cfg.SignatureEnabled {
+			v1.Use(middleware.VerifySignature(stores.Secrets, cfg.SignatureMaxSkew))
+		}
+		v1.Use(middleware.Authenticate(stores.Clients))
+	}
+	if cfg.RateLimitEnabled {
+		v1.Use(middleware.RateLimitClients(stores.RateLimits, ratelimit.Policy{
+			Default: cfg.RateLimitClient,
+			Routes:  cfg.RateLimitRoutes,
+		}))
+	}
 	{
 		accounts := v1.Group("/accounts")
 		{
-			accounts.POST("", idempotencyMw, accountH.Create)
-			accounts.GET("", accountH.List)
-			accounts.GET("/:id", accountH.GetByID)
-			accounts.DELETE("/:id", accountH.Delete)
-			accounts.GET("/:id/entries", entryH.ListByAccount)
-			accounts.GET("/:id/transactions", transactionH.ListByAccount)
+			accounts.POST("", accountsWrite, idempotencyMw, accountH.Create)
+			accounts.GET("", accountsRead, accountH.List)
+			accounts.GET("/:id", accountsRead, accountH.GetByID)
+			accounts.DELETE("/:id", accountsAdmin, accountH.Delete)
+			accounts.PATCH("/:id/status", accountsAdmin, accountH.UpdateStatus)
+			accounts.PATCH("/:id/limits", accountsAdmin, accountH.UpdateLimits)
+			accounts.GET("/:id/balance", accountsRead, balanceH.AsOf)
+			accounts.GET("/:id/balance/verify", accountsRead, balanceH.Verify)
+			accounts.GET("/:id/balance-history", accountsRead, balanceH.History)
+			accounts.GET("/:id/entries", transactionsRead, entryH.ListByAccount)
+			accounts.GET("/:id/transactions", transactionsRead, transactionH.ListByAccount)
+			accounts.POST("/:id/deposits", transfersWrite, idempotencyMw, transactionH.Deposit)
+			accounts.POST("/:id/withdrawals", transfersWrite, accountLimit, idempotencyMw, transactionH.Withdraw)
 		}
 
 		entries := v1.Group("/entries")
 		{
-			entries.GET("/:id", entryH.GetByID)
+			entries.GET("/:id", transactionsRead, entryH.GetByID)
 		}
 
 		transactions := v1.Group("/transactions")
 		{
-			transactions.POST("", idempotencyMw, transactionH.Transfer)
-			transactions.GET("/:id", transactionH.GetByID)
+			transactions.POST("", transfersWrite, accountLimit, idempotencyMw, transactionH.Transfer)
+			transactions.GET("/:id", transactionsRead, transactionH.GetByID)
+			transactions.GET("/:id/entries", transactionsRead, transactionH.ListEntries)
+			transactions.POST("/:id/reversals", transfersWrite, idempotencyMw, transactionH.Reverse)
+			transactions.POST("/:id/capture", transfersWrite, idempotencyMw, transactionH.Capture)
+			transactions.POST("/:id/void", transfersWrite, idempotencyMw, transactionH.Void)
 		}
+
+		v1.POST("/journal", transfersWrite, accountLimit, idempotencyMw, journalH.Create)
 	}
 
 	return router
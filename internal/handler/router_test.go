@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/auth"
+	"github.com/gabrielvieirabra/payments-ledger/internal/config"
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/idempotency"
+	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
+)
+
+type roleResolver struct{}
+
+// GetByKeyHash treats the API key as the role name.
+func (roleResolver) GetByKeyHash(_ context.Context, keyHash string) (domain.APIClient, error) {
+	for _, role := range []string{auth.RoleViewer, auth.RoleProcessor, auth.RoleOperator} {
+		if auth.HashKey(role) == keyHash {
+			return domain.APIClient{ID: uuid.New(), Name: role, Role: role}, nil
+		}
+	}
+	return domain.APIClient{}, repository.ErrAPIClientNotFound
+}
+
+// routePermissions lists the permission every /api/v1 route requires. The
+// requests are invalid on purpose so that permitted ones stop at validation
+// and never reach the (nil) services.
+var routePermissions = []struct {
+	method string
+	route  string
+	path   string
+	perm   auth.Permission
+}{
+	{http.MethodPost, "/api/v1/accounts", "/api/v1/accounts", auth.PermAccountsWrite},
+	{http.MethodGet, "/api/v1/accounts", "/api/v1/accounts?limit=0", auth.PermAccountsRead},
+	{http.MethodGet, "/api/v1/accounts/:id", "/api/v1/accounts/x", auth.PermAccountsRead},
+	{http.MethodDelete, "/api/v1/accounts/:id", "/api/v1/accounts/x", auth.PermAccountsAdmin},
+	{http.MethodPatch, "/api/v1/accounts/:id/status", "/api/v1/accounts/x/status", auth.PermAccountsAdmin},
+	{http.MethodPatch, "/api/v1/accounts/:id/limits", "/api/v1/accounts/x/limits", auth.PermAccountsAdmin},
+	{http.MethodGet, "/api/v1/accounts/:id/balance", "/api/v1/accounts/x/balance", auth.PermAccountsRead},
+	{http.MethodGet, "/api/v1/accounts/:id/balance/verify", "/api/v1/accounts/x/balance/verify", auth.PermAccountsRead},
+	{http.MethodGet, "/api/v1/accounts/:id/balance-history", "/api/v1/accounts/x/balance-history", auth.PermAccountsRead},
+	{http.MethodGet, "/api/v1/accounts/:id/entries", "/api/v1/accounts/x/entries", auth.PermTransactionsRead},
+	{http.MethodGet, "/api/v1/accounts/:id/transactions", "/api/v1/accounts/x/transactions", auth.PermTransactionsRead},
+	{http.MethodPost, "/api/v1/accounts/:id/deposits", "/api/v1/accounts/x/deposits", auth.PermTransfersWrite},
+	{http.MethodPost, "/api/v1/accounts/:id/withdrawals", "/api/v1/accounts/x/withdrawals", auth.PermTransfersWrite},
+	{http.MethodGet, "/api/v1/entries/:id", "/api/v1/entries/x", auth.PermTransactionsRead},
+	{http.MethodPost, "/api/v1/transactions", "/api/v1/transactions", auth.PermTransfersWrite},
+	{http.MethodGet, "/api/v1/transactions/:id", "/api/v1/transactions/x", auth.PermTransactionsRead},
+	{http.MethodGet, "/api/v1/transactions/:id/entries", "/api/v1/transactions/x/entries", auth.PermTransactionsRead},
+	{http.MethodPost, "/api/v1/transactions/:id/reversals", "/api/v1/transactions/x/reversals", auth.PermTransfersWrite},
+	{http.MethodPost, "/api/v1/transactions/:id/capture", "/api/v1/transactions/x/capture", auth.PermTransfersWrite},
+	{http.MethodPost, "/api/v1/transactions/:id/void", "/api/v1/transactions/x/void", auth.PermTransfersWrite},
+	{http.MethodPost, "/api/v1/journal", "/api/v1/journal", auth.PermTransfersWrite},
+}
+
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{AuthEnabled: true}
+	return NewRouter(nil, Services{}, Stores{
+		Idempotency: idempotency.NewMemoryStore(0, 1),
+		Clients:     roleResolver{},
+	}, cfg)
+}
+
+func TestRouter_RoutePermissions(t *testing.T) {
+	router := newTestRouter(t)
+
+	for _, rt := range routePermissions {
+		for _, role := range []string{auth.RoleViewer, auth.RoleProcessor, auth.RoleOperator} {
+			t.Run(rt.method+" "+rt.route+" as "+role, func(t *testing.T) {
+				req := httptest.NewRequest(rt.method, rt.path, strings.NewReader(""))
+				req.Header.Set("Authorization", "Bearer "+role)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				if auth.Allowed(role, rt.perm) {
+					if w.Code == http.StatusForbidden || w.Code == http.StatusUnauthorized {
+						t.Errorf("expected %s to be allowed, got %d", role, w.Code)
+					}
+					return
+				}
+				if w.Code != http.StatusForbidden {
+					t.Errorf("expected 403 for %s, got %d", role, w.Code)
+				}
+				if !strings.Contains(w.Body.String(), string(rt.perm)) {
+					t.Errorf("expected error to name permission %s, got %s", rt.perm, w.Body)
+				}
+			})
+		}
+	}
+}
+
+func TestRouter_OverdraftLimitRequiresAdmin(t *testing.T) {
+	router := newTestRouter(t)
+
+	body := `{"owner":"alice","currency":"USD","overdraft_limit":1000}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/accounts", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+auth.RoleProcessor)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d: %s", w.Code, w.Body)
+	}
+	if !strings.Contains(w.Body.String(), "permission_denied") {
+		t.Errorf("expected permission_denied, got %s", w.Body)
+	}
+}
+
+func TestRouter_RequiresAPIKey(t *testing.T) {
+	router := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", w.Code)
+	}
+}
+
+func TestRouter_EveryRouteHasPermission(t *testing.T) {
+	router := newTestRouter(t)
+
+	covered := make(map[string]bool, len(routePermissions))
+	for _, rt := range routePermissions {
+		covered[rt.method+" "+rt.route] = true
+	}
+
+	for _, r := range router.Routes() {
+		if !strings.HasPrefix(r.Path, "/api/v1") || r.Path == OpenAPIPath {
+			continue
+		}
+		if !covered[r.Method+" "+r.Path] {
+			t.Errorf("route %s %s has no entry in routePermissions", r.Method, r.Path)
+		}
+	}
+}
@@ -1,14 +1,17 @@
 package handler
 
 import (
+	"context"
 	"errors"
-	"log/slog"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/idempotency"
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
 	"github.com/gabrielvieirabra/payments-ledger/internal/service"
 )
 
@@ -23,72 +26,169 @@ func NewTransactionHandler(svc *service.TransactionService) *TransactionHandler
 func (h *TransactionHandler) Transfer(c *gin.Context) {
 	var req domain.CreateTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Validation(c, err)
 		return
 	}
 
-	result, err := h.svc.Transfer(c.Request.Context(), req)
+	ctx := c.Request.Context()
+	idempotency.Expect(ctx, http.StatusCreated)
+	result, err := h.svc.Transfer(ctx, req)
 	if err != nil {
-		switch {
-		case errors.Is(err, service.ErrSameAccount):
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		case errors.Is(err, service.ErrCurrencyMismatch):
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		case errors.Is(err, service.ErrInsufficientBalance):
-			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
-		case errors.Is(err, service.ErrAccountNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		default:
-			slog.Error("failed to process transfer", "error", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process transfer"})
-		}
+		problem.Respond(c, err, "failed to process transfer")
 		return
 	}
 
 	c.JSON(http.StatusCreated, result)
 }
 
-func (h *TransactionHandler) GetByID(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+func (h *TransactionHandler) Deposit(c *gin.Context) {
+	accountID, req, ok := bindFundingRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	idempotency.Expect(ctx, http.StatusCreated)
+	result, err := h.svc.Deposit(ctx, accountID, req)
+	if err != nil {
+		problem.Respond(c, err, "failed to process deposit")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (h *TransactionHandler) Withdraw(c *gin.Context) {
+	accountID, req, ok := bindFundingRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	idempotency.Expect(ctx, http.StatusCreated)
+	result, err := h.svc.Withdraw(ctx, accountID, req)
+	if err != nil {
+		problem.Respond(c, err, "failed to process withdrawal")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (h *TransactionHandler) Reverse(c *gin.Context) {
+	id, ok := parseID(c, "transaction")
+	if !ok {
+		return
+	}
+
+	// The body is optional: an empty request reverses the remaining amount
+	var req domain.CreateReversalRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		problem.Validation(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	idempotency.Expect(ctx, http.StatusCreated)
+	result, err := h.svc.Reverse(ctx, id, req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid transaction id"})
+		problem.Respond(c, err, "failed to process reversal")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (h *TransactionHandler) Capture(c *gin.Context) {
+	h.settle(c, h.svc.Capture, "failed to capture authorization")
+}
+
+func (h *TransactionHandler) Void(c *gin.Context) {
+	h.settle(c, h.svc.Void, "failed to void authorization")
+}
+
+func (h *TransactionHandler) settle(
+	c *gin.Context,
+	fn func(context.Context, uuid.UUID) (domain.TransactionResult, error),
+	failureMsg string,
+) {
+	id, ok := parseID(c, "transaction")
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	idempotency.Expect(ctx, http.StatusOK)
+	result, err := fn(ctx, id)
+	if err != nil {
+		problem.Respond(c, err, failureMsg)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func bindFundingRequest(c *gin.Context) (uuid.UUID, domain.CreateFundingRequest, bool) {
+	accountID, ok := parseID(c, "account")
+	if !ok {
+		return uuid.Nil, domain.CreateFundingRequest{}, false
+	}
+
+	var req domain.CreateFundingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Validation(c, err)
+		return uuid.Nil, domain.CreateFundingRequest{}, false
+	}
+
+	return accountID, req, true
+}
+
+func (h *TransactionHandler) GetByID(c *gin.Context) {
+	id, ok := parseID(c, "transaction")
+	if !ok {
 		return
 	}
 
 	txn, err := h.svc.GetByID(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, service.ErrTransactionNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
-			return
-		}
-		slog.Error("failed to get transaction", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get transaction"})
+		problem.Respond(c, err, "failed to get transaction")
 		return
 	}
 
 	c.JSON(http.StatusOK, txn)
 }
 
-func (h *TransactionHandler) ListByAccount(c *gin.Context) {
-	accountID, err := uuid.Parse(c.Param("id"))
+func (h *TransactionHandler) ListEntries(c *gin.Context) {
+	id, ok := parseID(c, "transaction")
+	if !ok {
+		return
+	}
+
+	entries, err := h.svc.ListEntries(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		problem.Respond(c, err, "failed to list transaction entries")
 		return
 	}
 
-	var params struct {
-		Limit  int32 `form:"limit,default=10" binding:"min=1,max=100"`
-		Offset int32 `form:"offset,default=0" binding:"min=0"`
+	c.JSON(http.StatusOK, entries)
+}
+
+func (h *TransactionHandler) ListByAccount(c *gin.Context) {
+	accountID, ok := parseID(c, "account")
+	if !ok {
+		return
 	}
+
+	var params domain.ListTransactionsParams
 	if err := c.ShouldBindQuery(&params); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Validation(c, err)
 		return
 	}
+	params.AccountID = accountID
 
-	transactions, err := h.svc.ListByAccount(c.Request.Context(), accountID, params.Limit, params.Offset)
+	transactions, err := h.svc.ListByAccount(c.Request.Context(), params)
 	if err != nil {
-		slog.Error("failed to list transactions", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list transactions"})
+		problem.Respond(c, err, "failed to list transactions")
 		return
 	}
 
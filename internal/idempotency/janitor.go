@@ -0,0 +1,45 @@
+package idempotency
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/metrics"
+)
+
+// Purger deletes up to limit expired keys and returns how many it removed.
+type Purger interface {
+	DeleteExpired(ctx context.Context, limit int) (int64, error)
+}
+
+// Janitor removes expired idempotency keys in bounded batches, so a large
+// backlog never turns into one long-running delete.
+type Janitor struct {
+	purger    Purger
+	batchSize int
+}
+
+func NewJanitor(purger Purger, batchSize int) *Janitor {
+	return &Janitor{purger: purger, batchSize: batchSize}
+}
+
+// Purge deletes batches until no expired keys are left or ctx is cancelled.
+func (j *Janitor) Purge(ctx context.Context) error {
+	var total int64
+	for ctx.Err() == nil {
+		n, err := j.purger.DeleteExpired(ctx, j.batchSize)
+		if err != nil {
+			return err
+		}
+		total += n
+		metrics.IdempotencyKeysPurged.Add(float64(n))
+		if n < int64(j.batchSize) {
+			break
+		}
+	}
+
+	if total > 0 {
+		slog.InfoContext(ctx, "purged expired idempotency keys", "count", total)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/metrics"
+)
+
+type fakePurger struct {
+	remaining int64
+	calls     int
+}
+
+func (f *fakePurger) DeleteExpired(_ context.Context, limit int) (int64, error) {
+	f.calls++
+	n := min(f.remaining, int64(limit))
+	f.remaining -= n
+	return n, nil
+}
+
+func TestJanitor_PurgesInBatches(t *testing.T) {
+	purger := &fakePurger{remaining: 250}
+	before := testutil.ToFloat64(metrics.IdempotencyKeysPurged)
+
+	if err := NewJanitor(purger, 100).Purge(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if purger.remaining != 0 {
+		t.Errorf("expected all keys purged, %d left", purger.remaining)
+	}
+	if purger.calls != 3 {
+		t.Errorf("expected 3 batches, got %d", purger.calls)
+	}
+	if got := testutil.ToFloat64(metrics.IdempotencyKeysPurged) - before; got != 250 {
+		t.Errorf("expected counter to grow by 250, got %v", got)
+	}
+}
+
+func TestJanitor_StopsOnCancel(t *testing.T) {
+	purger := &fakePurger{remaining: 1000}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := NewJanitor(purger, 100).Purge(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if purger.calls != 0 {
+		t.Errorf("expected no batches after cancel, got %d", purger.calls)
+	}
+}
@@ -0,0 +1,166 @@
+package idempotency
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
+)
+
+// MemoryStore keeps idempotency keys in process, for tests and single-node
+// development. Keys expire after the TTL and the least recently used completed
+// keys are evicted beyond capacity; keys still processing are never evicted.
+type MemoryStore struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	capacity int
+	order    *list.List // front is most recently used
+	items    map[string]*list.Element
+	now      func() time.Time
+}
+
+func NewMemoryStore(ttl time.Duration, capacity int) *MemoryStore {
+	return &MemoryStore{
+		ttl:      ttl,
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+func memoryKey(clientID *uuid.UUID, key, method, path string) string {
+	client := ""
+	if clientID != nil {
+		client = clientID.String()
+	}
+	return client + "\x00" + key + "\x00" + method + "\x00" + path
+}
+
+func (s *MemoryStore) Find(_ context.Context, clientID *uuid.UUID, key, method, path string) (domain.IdempotencyKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[memoryKey(clientID, key, method, path)]
+	if !ok {
+		return domain.IdempotencyKey{}, repository.ErrIdempotencyKeyNotFound
+	}
+	ik := el.Value.(domain.IdempotencyKey)
+	if !ik.ExpiresAt.After(s.now()) {
+		return domain.IdempotencyKey{}, repository.ErrIdempotencyKeyNotFound
+	}
+	s.order.MoveToFront(el)
+	return ik, nil
+}
+
+func (s *MemoryStore) Reserve(_ context.Context, clientID *uuid.UUID, key, method, path, requestHash string, lockTimeout time.Duration) (domain.IdempotencyKey, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	mk := memoryKey(clientID, key, method, path)
+	if el, ok := s.items[mk]; ok {
+		existing := el.Value.(domain.IdempotencyKey)
+		stale := existing.Status == domain.IdempotencyStatusProcessing && !existing.LockedAt.After(now.Add(-lockTimeout))
+		if existing.ExpiresAt.After(now) && !stale {
+			return domain.IdempotencyKey{}, false, nil
+		}
+		s.remove(el)
+	}
+
+	lockedAt := now
+	ik := domain.IdempotencyKey{
+		ID:             uuid.New(),
+		ClientID:       clientID,
+		IdempotencyKey: key,
+		Method:         method,
+		Path:           path,
+		RequestHash:    requestHash,
+		Status:         domain.IdempotencyStatusProcessing,
+		LockedAt:       &lockedAt,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(s.ttl),
+	}
+	s.items[mk] = s.order.PushFront(ik)
+	s.evict()
+	return ik, true, nil
+}
+
+func (s *MemoryStore) Complete(_ context.Context, reservation domain.IdempotencyKey, statusCode int, responseBody []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.reservation(reservation)
+	if !ok {
+		return nil
+	}
+	ik := el.Value.(domain.IdempotencyKey)
+	ik.Status = domain.IdempotencyStatusCompleted
+	ik.StatusCode = statusCode
+	ik.ResponseBody = append([]byte(nil), responseBody...)
+	ik.LockedAt = nil
+	el.Value = ik
+	s.evict()
+	return nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, reservation domain.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.reservation(reservation); ok {
+		s.remove(el)
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteExpired(_ context.Context, limit int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var deleted int64
+	for el := s.order.Back(); el != nil && deleted < int64(limit); {
+		prev := el.Prev()
+		if !el.Value.(domain.IdempotencyKey).ExpiresAt.After(now) {
+			s.remove(el)
+			deleted++
+		}
+		el = prev
+	}
+	return deleted, nil
+}
+
+// reservation returns the element still held by reservation, matching the
+// Postgres store which compares id and lock time.
+func (s *MemoryStore) reservation(reservation domain.IdempotencyKey) (*list.Element, bool) {
+	el, ok := s.items[memoryKey(reservation.ClientID, reservation.IdempotencyKey, reservation.Method, reservation.Path)]
+	if !ok {
+		return nil, false
+	}
+	ik := el.Value.(domain.IdempotencyKey)
+	if ik.ID != reservation.ID || ik.Status != domain.IdempotencyStatusProcessing {
+		return nil, false
+	}
+	return el, true
+}
+
+func (s *MemoryStore) evict() {
+	for el := s.order.Back(); el != nil && s.order.Len() > s.capacity; {
+		prev := el.Prev()
+		if el.Value.(domain.IdempotencyKey).Status != domain.IdempotencyStatusProcessing {
+			s.remove(el)
+		}
+		el = prev
+	}
+}
+
+func (s *MemoryStore) remove(el *list.Element) {
+	ik := s.order.Remove(el).(domain.IdempotencyKey)
+	delete(s.items, memoryKey(ik.ClientID, ik.IdempotencyKey, ik.Method, ik.Path))
+}
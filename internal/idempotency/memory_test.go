@@ -0,0 +1,119 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
+)
+
+func newTestStore(capacity int) (*MemoryStore, *time.Time) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(time.Hour, capacity)
+	store.now = func() time.Time { return now }
+	return store, &now
+}
+
+func TestMemoryStore_ReserveCompleteFind(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(10)
+
+	res, ok, err := store.Reserve(ctx, nil, "k", "POST", "/p", "hash", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected reservation, got ok=%v err=%v", ok, err)
+	}
+	if _, ok, _ := store.Reserve(ctx, nil, "k", "POST", "/p", "hash", time.Minute); ok {
+		t.Fatal("expected second reservation to fail")
+	}
+
+	if err := store.Complete(ctx, res, 201, []byte(`{}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found, err := store.Find(ctx, nil, "k", "POST", "/p")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found.StatusCode != 201 || string(found.ResponseBody) != `{}` || found.RequestHash != "hash" {
+		t.Errorf("unexpected stored key: %+v", found)
+	}
+}
+
+func TestMemoryStore_StaleReservationIsTakenOver(t *testing.T) {
+	ctx := context.Background()
+	store, now := newTestStore(10)
+
+	first, _, _ := store.Reserve(ctx, nil, "k", "POST", "/p", "hash", time.Minute)
+	*now = now.Add(2 * time.Minute)
+
+	second, ok, _ := store.Reserve(ctx, nil, "k", "POST", "/p", "hash", time.Minute)
+	if !ok {
+		t.Fatal("expected stale reservation to be taken over")
+	}
+
+	// The original holder can no longer complete or release the key
+	_ = store.Complete(ctx, first, 500, nil)
+	_ = store.Release(ctx, first)
+	found, err := store.Find(ctx, nil, "k", "POST", "/p")
+	if err != nil || found.ID != second.ID || found.StatusCode != 0 {
+		t.Errorf("expected the new reservation to survive, got %+v, %v", found, err)
+	}
+}
+
+func TestMemoryStore_Expiry(t *testing.T) {
+	ctx := context.Background()
+	store, now := newTestStore(10)
+
+	res, _, _ := store.Reserve(ctx, nil, "k", "POST", "/p", "hash", time.Minute)
+	_ = store.Complete(ctx, res, 201, []byte(`{}`))
+	*now = now.Add(2 * time.Hour)
+
+	if _, err := store.Find(ctx, nil, "k", "POST", "/p"); !errors.Is(err, repository.ErrIdempotencyKeyNotFound) {
+		t.Errorf("expected expired key to be missing, got %v", err)
+	}
+	if n, _ := store.DeleteExpired(ctx, 10); n != 1 {
+		t.Errorf("expected 1 key purged, got %d", n)
+	}
+}
+
+func TestMemoryStore_EvictsLeastRecentlyUsedCompletedKeys(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(2)
+
+	for _, k := range []string{"a", "b"} {
+		res, _, _ := store.Reserve(ctx, nil, k, "POST", "/p", "", time.Minute)
+		_ = store.Complete(ctx, res, 201, nil)
+	}
+	// Touch a so that b becomes the eviction candidate
+	_, _ = store.Find(ctx, nil, "a", "POST", "/p")
+	_, _, _ = store.Reserve(ctx, nil, "c", "POST", "/p", "", time.Minute)
+
+	if _, err := store.Find(ctx, nil, "b", "POST", "/p"); err == nil {
+		t.Error("expected b to be evicted")
+	}
+	if _, err := store.Find(ctx, nil, "a", "POST", "/p"); err != nil {
+		t.Error("expected a to be kept")
+	}
+	if _, ok, _ := store.Reserve(ctx, nil, "c", "POST", "/p", "", time.Minute); ok {
+		t.Error("expected processing key c not to be evicted")
+	}
+}
+
+func TestMemoryStore_KeysAreScopedPerClient(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(10)
+	a, b := uuid.New(), uuid.New()
+
+	if _, ok, _ := store.Reserve(ctx, &a, "k", "POST", "/p", "", time.Minute); !ok {
+		t.Fatal("expected reservation for client a")
+	}
+	if _, ok, _ := store.Reserve(ctx, &b, "k", "POST", "/p", "", time.Minute); !ok {
+		t.Error("expected client b to reserve the same key independently")
+	}
+	if _, err := store.Find(ctx, nil, "k", "POST", "/p"); err == nil {
+		t.Error("expected anonymous scope not to see client keys")
+	}
+}
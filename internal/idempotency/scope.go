@@ -0,0 +1,93 @@
+// Package idempotency lets services record the response to an idempotent
+// request inside their own database transaction, so that a committed change
+// and its idempotency key can never be stored separately.
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+)
+
+// ErrReservationLost means the key was taken over by another request after
+// its lock timed out; the transaction must not commit.
+var ErrReservationLost = errors.New("idempotency key reservation was lost")
+
+// TxRecorder completes a reserved key using the caller's transaction.
+type TxRecorder interface {
+	CompleteTx(ctx context.Context, tx pgx.Tx, reservation domain.IdempotencyKey, statusCode int, responseBody []byte) (bool, error)
+}
+
+// Scope is the idempotency state of one request, carried in its context.
+type Scope struct {
+	reservation domain.IdempotencyKey
+	recorder    TxRecorder
+	status      int
+	committed   atomic.Bool
+}
+
+func NewScope(reservation domain.IdempotencyKey, recorder TxRecorder) *Scope {
+	return &Scope{reservation: reservation, recorder: recorder}
+}
+
+type scopeKey struct{}
+
+func WithScope(ctx context.Context, scope *Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+func FromContext(ctx context.Context) *Scope {
+	scope, _ := ctx.Value(scopeKey{}).(*Scope)
+	return scope
+}
+
+// Expect sets the status code the handler answers with on success. Results
+// are only recorded in-transaction once it is known.
+func Expect(ctx context.Context, statusCode int) {
+	if scope := FromContext(ctx); scope != nil {
+		scope.status = statusCode
+	}
+}
+
+// Record stores response as the outcome of the request within tx. It is a
+// no-op when the request carries no idempotency key.
+func Record(ctx context.Context, tx pgx.Tx, response any) error {
+	scope := FromContext(ctx)
+	if scope == nil || scope.status == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("marshal idempotent response: %w", err)
+	}
+
+	ok, err := scope.recorder.CompleteTx(ctx, tx, scope.reservation, scope.status, body)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrReservationLost
+	}
+	return nil
+}
+
+// Committed marks the recorded outcome as durable. It must only be called
+// after the transaction passed to Record has committed.
+func Committed(ctx context.Context) {
+	if scope := FromContext(ctx); scope != nil && scope.status != 0 {
+		scope.committed.Store(true)
+	}
+}
+
+// IsCommitted reports whether a service already stored the response, in which
+// case the middleware must not store it again.
+func (s *Scope) IsCommitted() bool {
+	return s.committed.Load()
+}
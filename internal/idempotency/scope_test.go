@@ -0,0 +1,77 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+)
+
+type fakeRecorder struct {
+	ok         bool
+	statusCode int
+	body       string
+}
+
+func (f *fakeRecorder) CompleteTx(_ context.Context, _ pgx.Tx, _ domain.IdempotencyKey, statusCode int, responseBody []byte) (bool, error) {
+	f.statusCode = statusCode
+	f.body = string(responseBody)
+	return f.ok, nil
+}
+
+func TestRecord_WithoutScopeIsNoop(t *testing.T) {
+	if err := Record(context.Background(), nil, map[string]int{"amount": 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	Committed(context.Background())
+}
+
+func TestRecord_RequiresExpectedStatus(t *testing.T) {
+	rec := &fakeRecorder{ok: true}
+	scope := NewScope(domain.IdempotencyKey{}, rec)
+	ctx := WithScope(context.Background(), scope)
+
+	if err := Record(ctx, nil, map[string]int{"amount": 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	Committed(ctx)
+
+	if rec.statusCode != 0 || scope.IsCommitted() {
+		t.Error("expected nothing to be recorded before Expect")
+	}
+}
+
+func TestRecord_StoresResponseAndMarksCommitted(t *testing.T) {
+	rec := &fakeRecorder{ok: true}
+	scope := NewScope(domain.IdempotencyKey{}, rec)
+	ctx := WithScope(context.Background(), scope)
+	Expect(ctx, http.StatusCreated)
+
+	if err := Record(ctx, nil, map[string]int{"amount": 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.statusCode != http.StatusCreated || rec.body != `{"amount":1}` {
+		t.Errorf("unexpected record: %d %s", rec.statusCode, rec.body)
+	}
+	if scope.IsCommitted() {
+		t.Error("expected scope to stay uncommitted until Committed is called")
+	}
+
+	Committed(ctx)
+	if !scope.IsCommitted() {
+		t.Error("expected scope to be committed")
+	}
+}
+
+func TestRecord_LostReservation(t *testing.T) {
+	ctx := WithScope(context.Background(), NewScope(domain.IdempotencyKey{}, &fakeRecorder{ok: false}))
+	Expect(ctx, http.StatusCreated)
+
+	if err := Record(ctx, nil, struct{}{}); !errors.Is(err, ErrReservationLost) {
+		t.Errorf("expected ErrReservationLost, got %v", err)
+	}
+}
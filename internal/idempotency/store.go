@@ -0,0 +1,27 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+)
+
+const (
+	StorePostgres = "postgres"
+	StoreMemory   = "memory"
+)
+
+// Store persists idempotency keys for the middleware, scoped per API client
+// (nil for anonymous callers). Find returns
+// repository.ErrIdempotencyKeyNotFound for missing or expired keys. Stores
+// that can also write inside a database transaction implement TxRecorder.
+type Store interface {
+	Find(ctx context.Context, clientID *uuid.UUID, key, method, path string) (domain.IdempotencyKey, error)
+	Reserve(ctx context.Context, clientID *uuid.UUID, key, method, path, requestHash string, lockTimeout time.Duration) (domain.IdempotencyKey, bool, error)
+	Complete(ctx context.Context, reservation domain.IdempotencyKey, statusCode int, responseBody []byte) error
+	Release(ctx context.Context, reservation domain.IdempotencyKey) error
+	Purger
+}
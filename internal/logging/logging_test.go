@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewContextHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(WithRequestID(context.Background(), "req-1"),
+		trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+
+	logger.ErrorContext(ctx, "transfer failed")
+	logger.Error("no context")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(lines))
+	}
+
+	var withCtx, withoutCtx map[string]any
+	if err := json.Unmarshal(lines[0], &withCtx); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(lines[1], &withoutCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	if withCtx["request_id"] != "req-1" {
+		t.Errorf("expected request_id req-1, got %v", withCtx["request_id"])
+	}
+	if withCtx["trace_id"] != traceID.String() || withCtx["span_id"] != spanID.String() {
+		t.Errorf("expected trace and span ids, got %v / %v", withCtx["trace_id"], withCtx["span_id"])
+	}
+	if withCtx["component"] != "test" {
+		t.Errorf("expected attributes from With to be kept, got %v", withCtx)
+	}
+	if _, ok := withoutCtx["request_id"]; ok {
+		t.Errorf("expected no request_id without a request context, got %v", withoutCtx)
+	}
+}
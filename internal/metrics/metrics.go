@@ -0,0 +1,61 @@
+// Package metrics holds the application's Prometheus collectors. They are
+// registered with the default registry, which /metrics serves.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "ledger"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	GRPCRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "gRPC calls by full method name and status code.",
+	}, []string{"method", "code"})
+
+	GRPCRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "gRPC call latency by full method name.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// Transfers counts money movements by operation (transfer, deposit,
+	// capture, journal, ...) and outcome: "success" or the error that
+	// rejected them, e.g. "insufficient_balance".
+	Transfers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Money movements by operation and outcome.",
+	}, []string{"operation", "outcome"})
+
+	// IdempotencyRequests counts requests carrying an Idempotency-Key by
+	// result: "miss" (executed), "hit" (replayed), "in_flight" or "mismatch".
+	IdempotencyRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "idempotency_requests_total",
+		Help:      "Requests with an idempotency key by lookup result.",
+	}, []string{"result"})
+
+	IdempotencyKeysPurged = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "idempotency_keys_purged_total",
+		Help:      "Expired idempotency keys deleted by the janitor.",
+	})
+)
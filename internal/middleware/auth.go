@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/auth"
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
+	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
+)
+
+// ClientResolver looks up an active API client by the hash of its key.
+type ClientResolver interface {
+	GetByKeyHash(ctx context.Context, keyHash string) (domain.APIClient, error)
+}
+
+// Authenticate requires an "Authorization: Bearer <api key>" header and puts
+// the resolved client into the request context. Requests already
+// authenticated by VerifySignature are let through.
+func Authenticate(resolver ClientResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := auth.ClientFromContext(c.Request.Context()); ok {
+			c.Next()
+			return
+		}
+
+		scheme, key, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || key == "" {
+			unauthorized(c, "missing api key")
+			return
+		}
+
+		client, err := resolver.GetByKeyHash(c.Request.Context(), auth.HashKey(key))
+		if err != nil {
+			if errors.Is(err, repository.ErrAPIClientNotFound) {
+				unauthorized(c, "invalid api key")
+				return
+			}
+			slog.ErrorContext(c.Request.Context(), "failed to authenticate client", "error", err)
+			problem.Abort(c, problem.New(http.StatusInternalServerError, problem.CodeInternal, "internal error"))
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithClient(c.Request.Context(), client))
+		c.Next()
+	}
+}
+
+func unauthorized(c *gin.Context, msg string) {
+	c.Header("WWW-Authenticate", `Bearer realm="payments-ledger"`)
+	problem.Abort(c, problem.New(http.StatusUnauthorized, problem.CodeUnauthorized, msg))
+}
+
+// RequirePermission rejects callers whose role does not grant perm. It must
+// run after Authenticate.
+func RequirePermission(perm auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client, ok := auth.ClientFromContext(c.Request.Context())
+		if !ok {
+			unauthorized(c, "missing api key")
+			return
+		}
+		if !auth.Allowed(client.Role, perm) {
+			problem.Abort(c, problem.New(http.StatusForbidden, problem.CodePermissionDenied, "permission denied").
+				With("permission", perm).
+				With("role", client.Role))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/auth"
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
+)
+
+type fakeResolver map[string]domain.APIClient
+
+func (f fakeResolver) GetByKeyHash(_ context.Context, keyHash string) (domain.APIClient, error) {
+	client, ok := f[keyHash]
+	if !ok {
+		return domain.APIClient{}, repository.ErrAPIClientNotFound
+	}
+	return client, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	client := domain.APIClient{ID: uuid.New(), Name: "checkout"}
+	resolver := fakeResolver{auth.HashKey("pl_valid"): client}
+
+	router := gin.New()
+	router.GET("/", Authenticate(resolver), func(c *gin.Context) {
+		id := auth.ClientID(c.Request.Context())
+		if id == nil || *id != client.ID {
+			t.Errorf("expected client %s in context, got %v", client.ID, id)
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"valid key", "Bearer pl_valid", http.StatusNoContent},
+		{"case-insensitive scheme", "bearer pl_valid", http.StatusNoContent},
+		{"missing header", "", http.StatusUnauthorized},
+		{"wrong scheme", "Basic pl_valid", http.StatusUnauthorized},
+		{"unknown key", "Bearer pl_other", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, w.Code)
+			}
+			if tt.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected WWW-Authenticate header")
+			}
+		})
+	}
+}
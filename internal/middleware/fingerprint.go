@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// fingerprint hashes what makes a request unique for idempotency purposes: the
+// method, the concrete path (route parameters included) and the body. JSON
+// bodies are canonicalized first so that key order and whitespace do not
+// count as a different payload.
+func fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(canonicalJSON(body))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func canonicalJSON(body []byte) []byte {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return body
+	}
+	// Maps are marshaled with sorted keys
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return canonical
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import "testing"
+
+func TestFingerprint_IgnoresKeyOrderAndWhitespace(t *testing.T) {
+	a := fingerprint("POST", "/api/v1/transactions", []byte(`{"amount":100,"currency":"USD"}`))
+	b := fingerprint("POST", "/api/v1/transactions", []byte("{\n  \"currency\": \"USD\",\n  \"amount\": 100\n}"))
+
+	if a != b {
+		t.Error("expected equivalent JSON bodies to share a fingerprint")
+	}
+}
+
+func TestFingerprint_DetectsChanges(t *testing.T) {
+	base := fingerprint("POST", "/api/v1/transactions", []byte(`{"amount":100}`))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"amount", "POST", "/api/v1/transactions", `{"amount":101}`},
+		{"path", "POST", "/api/v1/accounts/other/deposits", `{"amount":100}`},
+		{"method", "PUT", "/api/v1/transactions", `{"amount":100}`},
+		{"large number precision", "POST", "/api/v1/transactions", `{"amount":100.0000000000000001}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if fingerprint(tt.method, tt.path, []byte(tt.body)) == base {
+				t.Errorf("expected a different fingerprint when %s changes", tt.name)
+			}
+		})
+	}
+}
+
+func TestFingerprint_NonJSONBody(t *testing.T) {
+	a := fingerprint("POST", "/x", []byte("not json"))
+	b := fingerprint("POST", "/x", []byte("not json "))
+
+	if a == b {
+		t.Error("expected raw bodies to be hashed byte for byte")
+	}
+}
@@ -2,16 +2,27 @@ package middleware
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
+	"github.com/gabrielvieirabra/payments-ledger/internal/auth"
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/idempotency"
+	"github.com/gabrielvieirabra/payments-ledger/internal/metrics"
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
 	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
 )
 
-const IdempotencyKeyHeader = "Idempotency-Key"
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
 
 type responseRecorder struct {
 	gin.ResponseWriter
@@ -23,7 +34,9 @@ func (r *responseRecorder) Write(b []byte) (int, error) {
 	return r.ResponseWriter.Write(b)
 }
 
-func Idempotency(repo *repository.IdempotencyRepository) gin.HandlerFunc {
+// Idempotency replays the stored response of requests carrying an
+// Idempotency-Key header and runs each key at most once.
+func Idempotency(store idempotency.Store, lockTimeout time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		key := c.GetHeader(IdempotencyKeyHeader)
 		if key == "" {
@@ -32,31 +45,54 @@ func Idempotency(repo *repository.IdempotencyRepository) gin.HandlerFunc {
 		}
 
 		if len(key) > 255 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "idempotency key must be at most 255 characters"})
-			c.Abort()
+			problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeIdempotencyKey, "idempotency key must be at most 255 characters"))
 			return
 		}
 
 		method := c.Request.Method
 		path := c.FullPath()
 
-		cached, err := repo.Find(c.Request.Context(), key, method, path)
-		if err == nil {
-			slog.Debug("idempotency cache hit",
-				"key", key,
-				"method", method,
-				"path", path,
-			)
-			c.Data(cached.StatusCode, "application/json", cached.ResponseBody)
-			c.Abort()
+		body, ok := bufferBody(c)
+		if !ok {
+			return
+		}
+		requestHash := fingerprint(method, c.Request.URL.Path, body)
+
+		ctx := c.Request.Context()
+		clientID := auth.ClientID(ctx)
+		reservation, reserved, err := store.Reserve(ctx, clientID, key, method, path, requestHash, lockTimeout)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to reserve idempotency key", "error", err)
+			problem.Abort(c, problem.New(http.StatusInternalServerError, problem.CodeInternal, "internal error"))
 			return
 		}
-		if !errors.Is(err, repository.ErrIdempotencyKeyNotFound) {
-			slog.Error("failed to check idempotency key", "error", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
-			c.Abort()
+		if !reserved {
+			replay(c, store, clientID, key, method, path, requestHash)
 			return
 		}
+		metrics.IdempotencyRequests.WithLabelValues("miss").Inc()
+
+		// Outcomes are recorded even if the client hangs up: the money may
+		// already have moved
+		storeCtx := context.WithoutCancel(ctx)
+		completed := false
+		defer func() {
+			// Runs on non-2xx responses and on panics, before gin.Recovery
+			if completed {
+				return
+			}
+			if err := store.Release(storeCtx, reservation); err != nil {
+				slog.ErrorContext(ctx, "failed to release idempotency key", "key", key, "error", err)
+			}
+		}()
+
+		// Services store the response in their own database transaction
+		// through the scope when the store supports it
+		var scope *idempotency.Scope
+		if recorder, ok := store.(idempotency.TxRecorder); ok {
+			scope = idempotency.NewScope(reservation, recorder)
+			c.Request = c.Request.WithContext(idempotency.WithScope(ctx, scope))
+		}
 
 		recorder := &responseRecorder{
 			ResponseWriter: c.Writer,
@@ -66,22 +102,63 @@ func Idempotency(repo *repository.IdempotencyRepository) gin.HandlerFunc {
 
 		c.Next()
 
-		if c.IsAborted() {
+		if scope != nil && scope.IsCommitted() {
+			completed = true
 			return
 		}
 
 		statusCode := c.Writer.Status()
-		responseBody := recorder.body.Bytes()
-
-		if statusCode < 200 || statusCode >= 300 {
+		if c.IsAborted() || statusCode < 200 || statusCode >= 300 {
 			return
 		}
 
-		if err := repo.Store(c.Request.Context(), key, method, path, statusCode, responseBody); err != nil {
-			slog.Error("failed to store idempotency key",
+		if err := store.Complete(storeCtx, reservation, statusCode, recorder.body.Bytes()); err != nil {
+			slog.ErrorContext(ctx, "failed to store idempotency key",
 				"key", key,
 				"error", err,
 			)
+			return
+		}
+		completed = true
+	}
+}
+
+// replay answers a request whose key is already taken, either with the stored
+// response or with a conflict while the first request is still running.
+func replay(c *gin.Context, store idempotency.Store, clientID *uuid.UUID, key, method, path, requestHash string) {
+	defer c.Abort()
+
+	cached, err := store.Find(c.Request.Context(), clientID, key, method, path)
+	if err != nil {
+		if errors.Is(err, repository.ErrIdempotencyKeyNotFound) {
+			// Released or expired since Reserve; the client can retry
+			metrics.IdempotencyRequests.WithLabelValues("in_flight").Inc()
+			problem.Abort(c, problem.New(http.StatusConflict, problem.CodeIdempotencyBusy, "request with this idempotency key is being processed, retry later"))
+			return
 		}
+		slog.ErrorContext(c.Request.Context(), "failed to check idempotency key", "error", err)
+		problem.Abort(c, problem.New(http.StatusInternalServerError, problem.CodeInternal, "internal error"))
+		return
 	}
+
+	if cached.RequestHash != "" && cached.RequestHash != requestHash {
+		metrics.IdempotencyRequests.WithLabelValues("mismatch").Inc()
+		problem.Abort(c, problem.New(http.StatusUnprocessableEntity, problem.CodeIdempotencyReused, "idempotency key was already used with a different request"))
+		return
+	}
+
+	if cached.Status == domain.IdempotencyStatusProcessing {
+		metrics.IdempotencyRequests.WithLabelValues("in_flight").Inc()
+		problem.Abort(c, problem.New(http.StatusConflict, problem.CodeIdempotencyBusy, "request with this idempotency key is being processed, retry later"))
+		return
+	}
+
+	slog.DebugContext(c.Request.Context(), "idempotency cache hit",
+		"key", key,
+		"method", method,
+		"path", path,
+	)
+	metrics.IdempotencyRequests.WithLabelValues("hit").Inc()
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Data(cached.StatusCode, "application/json", cached.ResponseBody)
 }
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/idempotency"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func newIdempotentRouter(store idempotency.Store, status int, calls *int) *gin.Engine {
+	router := gin.New()
+	router.POST("/transfers", Idempotency(store, time.Minute), func(c *gin.Context) {
+		*calls++
+		c.JSON(status, gin.H{"call": *calls})
+	})
+	return router
+}
+
+func doRequest(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/transfers", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_MissThenHit(t *testing.T) {
+	var calls int
+	router := newIdempotentRouter(idempotency.NewMemoryStore(time.Hour, 100), http.StatusCreated, &calls)
+
+	first := doRequest(router, "key-1", `{"amount":100}`)
+	if first.Code != http.StatusCreated || first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("unexpected first response: %d %v", first.Code, first.Header())
+	}
+
+	second := doRequest(router, "key-1", `{"amount": 100}`)
+	if second.Code != http.StatusCreated {
+		t.Errorf("expected replayed status 201, got %d", second.Code)
+	}
+	if second.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Error("expected replayed header on cached response")
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("expected replayed body %s, got %s", first.Body, second.Body)
+	}
+	if calls != 1 {
+		t.Errorf("expected handler to run once, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_WithoutKeyAlwaysRuns(t *testing.T) {
+	var calls int
+	router := newIdempotentRouter(idempotency.NewMemoryStore(time.Hour, 100), http.StatusCreated, &calls)
+
+	doRequest(router, "", `{}`)
+	doRequest(router, "", `{}`)
+
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestIdempotency_Non2xxIsNotStored(t *testing.T) {
+	var calls int
+	router := newIdempotentRouter(idempotency.NewMemoryStore(time.Hour, 100), http.StatusUnprocessableEntity, &calls)
+
+	doRequest(router, "key-1", `{"amount":100}`)
+	w := doRequest(router, "key-1", `{"amount":100}`)
+
+	if calls != 2 {
+		t.Errorf("expected failed request to be retried, handler ran %d times", calls)
+	}
+	if w.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Error("expected failed response not to be replayed")
+	}
+}
+
+func TestIdempotency_OversizedKey(t *testing.T) {
+	var calls int
+	router := newIdempotentRouter(idempotency.NewMemoryStore(time.Hour, 100), http.StatusCreated, &calls)
+
+	w := doRequest(router, strings.Repeat("k", 256), `{}`)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+	if calls != 0 {
+		t.Error("expected handler not to run")
+	}
+}
+
+func TestIdempotency_DifferentPayload(t *testing.T) {
+	var calls int
+	router := newIdempotentRouter(idempotency.NewMemoryStore(time.Hour, 100), http.StatusCreated, &calls)
+
+	doRequest(router, "key-1", `{"amount":100}`)
+	w := doRequest(router, "key-1", `{"amount":200}`)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", w.Code)
+	}
+	if calls != 1 {
+		t.Errorf("expected handler to run once, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_InFlight(t *testing.T) {
+	var calls int
+	store := idempotency.NewMemoryStore(time.Hour, 100)
+	router := newIdempotentRouter(store, http.StatusCreated, &calls)
+
+	body := `{"amount":100}`
+	_, ok, _ := store.Reserve(context.Background(), nil, "key-1", http.MethodPost, "/transfers",
+		fingerprint(http.MethodPost, "/transfers", []byte(body)), time.Minute)
+	if !ok {
+		t.Fatal("expected reservation")
+	}
+
+	w := doRequest(router, "key-1", body)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", w.Code)
+	}
+	if calls != 0 {
+		t.Error("expected handler not to run while the key is processing")
+	}
+}
+
+func TestIdempotency_PanicReleasesKey(t *testing.T) {
+	store := idempotency.NewMemoryStore(time.Hour, 100)
+	router := gin.New()
+	router.Use(gin.Recovery())
+	var calls int
+	router.POST("/transfers", Idempotency(store, time.Minute), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	doRequest(router, "key-1", `{}`)
+	w := doRequest(router, "key-1", `{}`)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected retry after panic to succeed, got %d", w.Code)
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/metrics"
+)
+
+func TestMetrics_LabelsByRoutePattern(t *testing.T) {
+	router := gin.New()
+	router.Use(Metrics())
+	router.GET("/accounts/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	matched := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/accounts/:id", "204")
+	unmatched := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "unmatched", "404")
+	beforeMatched, beforeUnmatched := testutil.ToFloat64(matched), testutil.ToFloat64(unmatched)
+
+	for _, path := range []string{"/accounts/1", "/accounts/2", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := testutil.ToFloat64(matched) - beforeMatched; got != 2 {
+		t.Errorf("expected 2 requests on /accounts/:id, got %v", got)
+	}
+	if got := testutil.ToFloat64(unmatched) - beforeUnmatched; got != 1 {
+		t.Errorf("expected 1 unmatched request, got %v", got)
+	}
+}
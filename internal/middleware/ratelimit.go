@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/auth"
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
+	"github.com/gabrielvieirabra/payments-ledger/internal/ratelimit"
+)
+
+// RateLimitClients throttles each API client, or each remote address when
+// authentication is off, using the policy's limit for the matched route.
+func RateLimitClients(store ratelimit.Store, policy ratelimit.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, bucket := policy.For(c.Request.Method, c.FullPath())
+
+		caller := "ip:" + c.ClientIP()
+		if id := auth.ClientID(c.Request.Context()); id != nil {
+			caller = "client:" + id.String()
+		}
+		if takeToken(c, store, caller+":"+bucket, limit) {
+			c.Next()
+		}
+	}
+}
+
+// RateLimitAccounts throttles debits from a single account, however many
+// clients are sending them. The debited account comes from the :id route
+// parameter, the from_account_id field of a transfer or the negative legs of
+// a journal. Credited accounts are not limited, so that payers cannot
+// throttle a payee. Accounts are keyed on their parsed UUID, so every
+// spelling of an id shares one bucket, and a request takes a token from
+// every debited account or from none. Requests without a valid account pass
+// through and are left to the handler to validate.
+func RateLimitAccounts(store ratelimit.Store, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var keys []string
+		debit := func(account string) {
+			id, err := uuid.Parse(account)
+			if err != nil {
+				return
+			}
+			if key := "account:" + id.String(); !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+
+		if id := c.Param("id"); id != "" {
+			debit(id)
+		} else {
+			body, ok := bufferBody(c)
+			if !ok {
+				return
+			}
+			var req struct {
+				FromAccountID string `json:"from_account_id"`
+				Legs          []struct {
+					AccountID string `json:"account_id"`
+					Amount    int64  `json:"amount"`
+				} `json:"legs"`
+			}
+			_ = json.Unmarshal(body, &req)
+			if req.FromAccountID != "" {
+				debit(req.FromAccountID)
+			}
+			for _, leg := range req.Legs {
+				if leg.Amount < 0 {
+					debit(leg.AccountID)
+				}
+			}
+		}
+
+		if len(keys) == 0 || takeTokens(c, store, keys, limit) {
+			c.Next()
+		}
+	}
+}
+
+// takeToken takes a token from the bucket at key, aborting with 429 when it
+// is empty.
+func takeToken(c *gin.Context, store ratelimit.Store, key string, limit ratelimit.Limit) bool {
+	return takeTokens(c, store, []string{key}, limit)
+}
+
+// takeTokens takes a token from each bucket in keys, or from none of them,
+// aborting with 429 when any is empty.
+func takeTokens(c *gin.Context, store ratelimit.Store, keys []string, limit ratelimit.Limit) bool {
+	if limit.Unlimited() {
+		return true
+	}
+
+	res, err := store.TakeAll(c.Request.Context(), keys, limit)
+	if err != nil {
+		// Fail open: an unavailable limiter should not take payments down
+		slog.ErrorContext(c.Request.Context(), "failed to check rate limit", "keys", keys, "error", err)
+		return true
+	}
+	if !res.Allowed {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+		problem.Abort(c, problem.New(http.StatusTooManyRequests, problem.CodeRateLimited, "rate limit exceeded"))
+		return false
+	}
+	return true
+}
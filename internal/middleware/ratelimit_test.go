@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/ratelimit"
+)
+
+func TestRateLimitClients(t *testing.T) {
+	policy := ratelimit.Policy{
+		Default: ratelimit.Limit{Requests: 2, Per: time.Minute},
+		Routes:  map[string]ratelimit.Limit{"POST /journal": {Requests: 1, Per: time.Minute}},
+	}
+	router := gin.New()
+	router.Use(RateLimitClients(ratelimit.NewMemoryStore(), policy))
+	router.GET("/accounts", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.POST("/journal", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	if w := do(http.MethodPost, "/journal"); w.Code != http.StatusNoContent {
+		t.Fatalf("expected first journal request to pass, got %d", w.Code)
+	}
+	w := do(http.MethodPost, "/journal")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 for second journal request, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("expected Retry-After 60, got %q", got)
+	}
+
+	// The journal override has its own bucket, leaving the default untouched
+	for i := range 2 {
+		if w := do(http.MethodGet, "/accounts"); w.Code != http.StatusNoContent {
+			t.Fatalf("request %d: expected accounts request to pass, got %d", i, w.Code)
+		}
+	}
+	if w := do(http.MethodGet, "/accounts"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 once the default bucket is empty, got %d", w.Code)
+	}
+}
+
+func TestRateLimitAccounts(t *testing.T) {
+	limit := ratelimit.Limit{Requests: 1, Per: time.Minute}
+	router := gin.New()
+	router.Use(BodySizeLimit())
+	accounts := RateLimitAccounts(ratelimit.NewMemoryStore(), limit)
+	handler := func(c *gin.Context) {
+		var req struct {
+			FromAccountID string `json:"from_account_id"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusCreated)
+	}
+	router.POST("/transactions", accounts, handler)
+	router.POST("/accounts/:id/withdrawals", accounts, handler)
+	router.POST("/journal", accounts, handler)
+
+	do := func(path, body string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return w.Code
+	}
+	a, b, c, d := uuid.NewString(), uuid.NewString(), uuid.NewString(), uuid.NewString()
+
+	if code := do("/transactions", `{"from_account_id":"`+a+`"}`); code != http.StatusCreated {
+		t.Fatalf("expected first transfer from a to pass, got %d", code)
+	}
+	if code := do("/transactions", `{"from_account_id":"`+a+`"}`); code != http.StatusTooManyRequests {
+		t.Fatalf("expected second transfer from a to be limited, got %d", code)
+	}
+	// Other spellings of the same id share its bucket
+	if code := do("/accounts/"+strings.ToUpper(a)+"/withdrawals", `{}`); code != http.StatusTooManyRequests {
+		t.Fatalf("expected withdrawal from upper-case a to share its bucket, got %d", code)
+	}
+	if code := do("/transactions", `{"from_account_id":"{`+a+`}"}`); code != http.StatusTooManyRequests {
+		t.Fatalf("expected transfer from braced a to share its bucket, got %d", code)
+	}
+	// Credits are not limited
+	if code := do("/transactions", `{"from_account_id":"`+b+`","to_account_id":"`+a+`"}`); code != http.StatusCreated {
+		t.Fatalf("expected transfer from b to a to pass, got %d", code)
+	}
+	if code := do("/journal", `{"legs":[{"account_id":"`+a+`","amount":5},{"account_id":"`+c+`","amount":-5}]}`); code != http.StatusCreated {
+		t.Fatalf("expected journal crediting a to pass, got %d", code)
+	}
+	// A rejected journal takes no tokens from its other debited accounts
+	if code := do("/journal", `{"legs":[{"account_id":"`+d+`","amount":-5},{"account_id":"`+b+`","amount":-5},{"account_id":"`+a+`","amount":10}]}`); code != http.StatusTooManyRequests {
+		t.Fatalf("expected journal debiting b to be limited, got %d", code)
+	}
+	if code := do("/accounts/"+d+"/withdrawals", `{}`); code != http.StatusCreated {
+		t.Fatalf("expected withdrawal from d to pass after the rejected journal, got %d", code)
+	}
+	if code := do("/accounts/not-a-uuid/withdrawals", `{}`); code != http.StatusCreated {
+		t.Fatalf("expected malformed id to reach the handler, got %d", code)
+	}
+	if code := do("/transactions", `not json`); code != http.StatusBadRequest {
+		t.Fatalf("expected invalid body to reach the handler, got %d", code)
+	}
+}
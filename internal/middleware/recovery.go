@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
+)
+
+// Recovery turns panics into 500 responses and logs them, with their stack,
+// through slog instead of gin's plain-text writer.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		slog.ErrorContext(c.Request.Context(), "panic recovered", "error", err, "stack", string(debug.Stack()))
+		problem.Abort(c, problem.New(http.StatusInternalServerError, problem.CodeInternal, "internal error"))
+	})
+}
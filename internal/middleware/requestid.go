@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/logging"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID accepts the caller's X-Request-ID, or generates one, stores it in
+// the request context for logging and echoes it in the response header.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !logging.ValidRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
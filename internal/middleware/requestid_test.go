@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/logging"
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
+)
+
+func TestRequestID(t *testing.T) {
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/", func(c *gin.Context) {
+		problem.Abort(c, problem.New(http.StatusNotFound, "account_not_found", "account not found"))
+	})
+
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{"accepts caller id", "support-1234", "support-1234"},
+		{"generates when missing", "", ""},
+		{"replaces id with spaces", "a b", ""},
+		{"replaces oversized id", strings.Repeat("x", 129), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIDHeader)
+			if tt.expected != "" && id != tt.expected {
+				t.Errorf("expected request id %q, got %q", tt.expected, id)
+			}
+			if tt.expected == "" {
+				if _, err := uuid.Parse(id); err != nil {
+					t.Errorf("expected a generated uuid, got %q", id)
+				}
+			}
+
+			var body map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body["request_id"] != id || body["detail"] != "account not found" {
+				t.Errorf("expected error body to echo request id %q, got %v", id, body)
+			}
+		})
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(logging.NewContextHandler(slog.NewJSONHandler(&buf, nil))))
+	defer slog.SetDefault(prev)
+
+	router := gin.New()
+	router.Use(RequestID(), AccessLog(), Recovery())
+	router.GET("/accounts/:id", func(c *gin.Context) { panic("boom") })
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts/42", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+
+	var records []map[string]any
+	for line := range bytes.Lines(buf.Bytes()) {
+		var rec map[string]any
+		if err := json.Unmarshal(line, &rec); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected panic and access log records, got %d", len(records))
+	}
+
+	panicRec, access := records[0], records[1]
+	if panicRec["msg"] != "panic recovered" || panicRec["request_id"] != "req-42" {
+		t.Errorf("unexpected panic record %v", panicRec)
+	}
+	if access["msg"] != "request" || access["level"] != "ERROR" {
+		t.Errorf("expected error-level access log, got %v", access)
+	}
+	if access["request_id"] != "req-42" || access["route"] != "/accounts/:id" || access["status"] != float64(500) {
+		t.Errorf("unexpected access log fields %v", access)
+	}
+}
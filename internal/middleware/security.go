@@ -1,9 +1,14 @@
 package middleware
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
 )
 
 const maxBodySize = 1 << 20 // 1 MB
@@ -11,11 +16,28 @@ const maxBodySize = 1 << 20 // 1 MB
 func BodySizeLimit() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.Request.ContentLength > maxBodySize {
-			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
-			c.Abort()
+			problem.Abort(c, problem.New(http.StatusRequestEntityTooLarge, problem.CodeBodyTooLarge, "request body too large"))
 			return
 		}
 		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize)
 		c.Next()
 	}
 }
+
+// bufferBody reads the request body, already capped by BodySizeLimit, and
+// restores it so that later handlers can bind it. On failure it writes the
+// error response and aborts.
+func bufferBody(c *gin.Context) ([]byte, bool) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			problem.Abort(c, problem.New(http.StatusRequestEntityTooLarge, problem.CodeBodyTooLarge, "request body too large"))
+		} else {
+			problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeInvalidRequest, "failed to read request body"))
+		}
+		return nil, false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, true
+}
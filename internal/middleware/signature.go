@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/auth"
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
+	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
+)
+
+// SecretResolver looks up an active API client and its signing secrets.
+type SecretResolver interface {
+	GetByID(ctx context.Context, id uuid.UUID) (domain.APIClient, error)
+	ActiveSecrets(ctx context.Context, clientID uuid.UUID) ([]string, error)
+}
+
+// VerifySignature authenticates requests that carry an X-Signature header: an
+// HMAC-SHA256 of the method, request URI, X-Timestamp and body, keyed by one
+// of the X-Client-ID client's active secrets. Timestamps further than maxSkew
+// from now are rejected so captured requests cannot be replayed later.
+// Requests without a signature are passed on to Authenticate.
+func VerifySignature(resolver SecretResolver, maxSkew time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		signature := c.GetHeader(auth.SignatureHeader)
+		if signature == "" {
+			c.Next()
+			return
+		}
+
+		clientID, err := uuid.Parse(c.GetHeader(auth.ClientIDHeader))
+		if err != nil {
+			unauthorized(c, "invalid client id")
+			return
+		}
+
+		timestamp := c.GetHeader(auth.TimestampHeader)
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			unauthorized(c, "invalid timestamp")
+			return
+		}
+		if skew := time.Since(time.Unix(unix, 0)); skew > maxSkew || skew < -maxSkew {
+			unauthorized(c, "timestamp outside allowed window")
+			return
+		}
+
+		body, ok := bufferBody(c)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		client, err := resolver.GetByID(ctx, clientID)
+		if err != nil {
+			if errors.Is(err, repository.ErrAPIClientNotFound) {
+				unauthorized(c, "invalid signature")
+				return
+			}
+			slog.ErrorContext(ctx, "failed to authenticate client", "error", err)
+			problem.Abort(c, problem.New(http.StatusInternalServerError, problem.CodeInternal, "internal error"))
+			return
+		}
+		secrets, err := resolver.ActiveSecrets(ctx, clientID)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to load signing secrets", "error", err)
+			problem.Abort(c, problem.New(http.StatusInternalServerError, problem.CodeInternal, "internal error"))
+			return
+		}
+
+		if !auth.VerifySignature(secrets, signature, c.Request.Method, c.Request.URL.RequestURI(), timestamp, body) {
+			unauthorized(c, "invalid signature")
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithClient(ctx, client))
+		c.Next()
+	}
+}
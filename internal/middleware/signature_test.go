@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/auth"
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
+)
+
+type fakeSecrets struct {
+	client  domain.APIClient
+	secrets []string
+}
+
+func (f fakeSecrets) GetByID(_ context.Context, id uuid.UUID) (domain.APIClient, error) {
+	if id != f.client.ID {
+		return domain.APIClient{}, repository.ErrAPIClientNotFound
+	}
+	return f.client, nil
+}
+
+func (f fakeSecrets) ActiveSecrets(_ context.Context, _ uuid.UUID) ([]string, error) {
+	return f.secrets, nil
+}
+
+func TestVerifySignature(t *testing.T) {
+	client := domain.APIClient{ID: uuid.New(), Name: "checkout", Role: auth.RoleProcessor}
+	resolver := fakeSecrets{client: client, secrets: []string{"old", "new"}}
+
+	router := gin.New()
+	router.Use(BodySizeLimit())
+	router.POST("/transfers", VerifySignature(resolver, 5*time.Minute), func(c *gin.Context) {
+		id := auth.ClientID(c.Request.Context())
+		if id == nil || *id != client.ID {
+			c.Status(http.StatusTeapot)
+			return
+		}
+		var req struct {
+			Amount int `json:"amount"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || req.Amount != 100 {
+			t.Errorf("expected body to be readable after verification, got %+v (%v)", req, err)
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	body := `{"amount":100}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	future := strconv.FormatInt(time.Now().Add(10*time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		clientID  string
+		timestamp string
+		signature string
+		body      string
+		status    int
+	}{
+		{"current secret", client.ID.String(), now, auth.Sign("new", "POST", "/transfers", now, []byte(body)), body, http.StatusNoContent},
+		{"previous secret", client.ID.String(), now, auth.Sign("old", "POST", "/transfers", now, []byte(body)), body, http.StatusNoContent},
+		{"unknown secret", client.ID.String(), now, auth.Sign("other", "POST", "/transfers", now, []byte(body)), body, http.StatusUnauthorized},
+		{"tampered body", client.ID.String(), now, auth.Sign("new", "POST", "/transfers", now, []byte(body)), `{"amount":900}`, http.StatusUnauthorized},
+		{"stale timestamp", client.ID.String(), stale, auth.Sign("new", "POST", "/transfers", stale, []byte(body)), body, http.StatusUnauthorized},
+		{"future timestamp", client.ID.String(), future, auth.Sign("new", "POST", "/transfers", future, []byte(body)), body, http.StatusUnauthorized},
+		{"unknown client", uuid.NewString(), now, auth.Sign("new", "POST", "/transfers", now, []byte(body)), body, http.StatusUnauthorized},
+		{"malformed client", "checkout", now, auth.Sign("new", "POST", "/transfers", now, []byte(body)), body, http.StatusUnauthorized},
+		{"unsigned", "", "", "", body, http.StatusTeapot},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/transfers", strings.NewReader(tt.body))
+			if tt.signature != "" {
+				req.Header.Set(auth.ClientIDHeader, tt.clientID)
+				req.Header.Set(auth.TimestampHeader, tt.timestamp)
+				req.Header.Set(auth.SignatureHeader, tt.signature)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
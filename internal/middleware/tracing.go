@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/logging"
+)
+
+var tracer = otel.Tracer("github.com/gabrielvieirabra/payments-ledger/internal/middleware")
+
+// Tracing starts a server span per request, continuing the caller's trace
+// when it sends a traceparent header.
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		method := c.Request.Method
+		name := method
+		attrs := []trace.SpanStartOption{
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(c.Request.URL.Path),
+			),
+		}
+		if id := logging.RequestID(ctx); id != "" {
+			attrs = append(attrs, trace.WithAttributes(attribute.String("request.id", id)))
+		}
+		if route := c.FullPath(); route != "" {
+			name += " " + route
+			attrs = append(attrs, trace.WithAttributes(semconv.HTTPRoute(route)))
+		}
+
+		ctx, span := tracer.Start(ctx, name, attrs...)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	router := gin.New()
+	router.Use(Tracing())
+	router.GET("/accounts/:id", func(c *gin.Context) {
+		if !trace.SpanContextFromContext(c.Request.Context()).IsValid() {
+			t.Error("expected handler context to carry the request span")
+		}
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /accounts/:id" {
+		t.Errorf("expected span named after the route, got %q", span.Name())
+	}
+	if span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("expected server span, got %v", span.SpanKind())
+	}
+	if got := span.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected incoming trace to be continued, got trace %s", got)
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("expected 5xx to mark the span as failed, got %v", span.Status())
+	}
+}
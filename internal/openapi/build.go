@@ -0,0 +1,220 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Route describes one operation. Params, Body and Response are zero values of
+// the types bound from the query string, bound from the body and written on
+// success; nil means none.
+type Route struct {
+	Method      string
+	Path        string // gin syntax, e.g. /accounts/:id; path parameters are UUIDs
+	OperationID string
+	Summary     string
+	Description string
+	Tag         string
+
+	Params       any
+	Body         any
+	BodyOptional bool
+
+	Status       int
+	Response     any
+	ResponseType string // defaults to application/json
+
+	// Errors lists statuses beyond the ones every route of its kind may
+	// return (400 and 413 on input, 409 and 422 on idempotency conflicts,
+	// 401/403/429 unless public, 500)
+	Errors     []int
+	Idempotent bool
+	Public     bool
+}
+
+// Spec is the input of Build.
+type Spec struct {
+	Info            Info
+	SecuritySchemes map[string]SecurityScheme
+	Security        []SecurityRequirement
+	// Problem is the type of error bodies, written as ProblemType
+	Problem     any
+	ProblemType string
+	Routes      []Route
+}
+
+type generator struct {
+	schemas map[string]*Schema
+}
+
+func Build(spec Spec) *Document {
+	g := &generator{schemas: map[string]*Schema{}}
+	doc := &Document{
+		OpenAPI:  Version,
+		Info:     spec.Info,
+		Paths:    map[string]map[string]*Operation{},
+		Security: spec.Security,
+		Components: Components{
+			Schemas:         g.schemas,
+			SecuritySchemes: spec.SecuritySchemes,
+		},
+	}
+
+	var problem *Schema
+	if spec.Problem != nil {
+		problem = g.schemaFor(reflect.TypeOf(spec.Problem))
+	}
+
+	for _, r := range spec.Routes {
+		path, pathParams := convertPath(r.Path)
+		op := &Operation{
+			OperationID: r.OperationID,
+			Summary:     r.Summary,
+			Description: r.Description,
+			Parameters:  pathParams,
+			Responses:   map[string]Response{},
+		}
+		if r.Tag != "" {
+			op.Tags = []string{r.Tag}
+		}
+		if r.Public {
+			op.Security = []SecurityRequirement{{}}
+		}
+		if r.Idempotent {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:        "Idempotency-Key",
+				In:          "header",
+				Description: "Replays the first successful response when the request is retried",
+				Schema:      &Schema{Type: "string", MaxLength: ptr(255)},
+			})
+		}
+		if r.Params != nil {
+			op.Parameters = append(op.Parameters, g.queryParams(reflect.TypeOf(r.Params))...)
+		}
+		if r.Body != nil {
+			op.RequestBody = &RequestBody{
+				Required: !r.BodyOptional,
+				Content: map[string]MediaType{
+					"application/json": {Schema: g.schemaFor(reflect.TypeOf(r.Body))},
+				},
+			}
+		}
+
+		success := Response{Description: http.StatusText(r.Status)}
+		if r.Response != nil {
+			contentType := r.ResponseType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			success.Content = map[string]MediaType{
+				contentType: {Schema: g.schemaFor(reflect.TypeOf(r.Response))},
+			}
+		}
+		op.Responses[strconv.Itoa(r.Status)] = success
+
+		if problem != nil {
+			for _, status := range errorStatuses(r, len(pathParams) > 0) {
+				resp := Response{
+					Description: http.StatusText(status),
+					Content:     map[string]MediaType{spec.ProblemType: {Schema: problem}},
+				}
+				if status == http.StatusTooManyRequests {
+					resp.Headers = map[string]Header{
+						"Retry-After": {Description: "Seconds until the request may be retried", Schema: &Schema{Type: "integer"}},
+					}
+				}
+				op.Responses[strconv.Itoa(status)] = resp
+			}
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*Operation{}
+		}
+		doc.Paths[path][strings.ToLower(r.Method)] = op
+	}
+	return doc
+}
+
+// Operation returns the operation documented for a gin method and path.
+func (d *Document) Operation(method, ginPath string) (*Operation, bool) {
+	path, _ := convertPath(ginPath)
+	op, ok := d.Paths[path][strings.ToLower(method)]
+	return op, ok
+}
+
+func errorStatuses(r Route, hasPathParams bool) []int {
+	statuses := append([]int{http.StatusInternalServerError}, r.Errors...)
+	if r.Params != nil || r.Body != nil || hasPathParams {
+		statuses = append(statuses, http.StatusBadRequest)
+	}
+	if r.Body != nil {
+		statuses = append(statuses, http.StatusRequestEntityTooLarge)
+	}
+	if r.Idempotent {
+		// Key in flight, or reused with another request
+		statuses = append(statuses, http.StatusConflict, http.StatusUnprocessableEntity)
+	}
+	if !r.Public {
+		statuses = append(statuses, http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests)
+	}
+	sort.Ints(statuses)
+	return slices.Compact(statuses)
+}
+
+// convertPath turns gin's :name segments into OpenAPI {name} templates.
+func convertPath(ginPath string) (string, []Parameter) {
+	segments := strings.Split(ginPath, "/")
+	var params []Parameter
+	for i, seg := range segments {
+		name, ok := strings.CutPrefix(seg, ":")
+		if !ok {
+			continue
+		}
+		segments[i] = "{" + name + "}"
+		params = append(params, Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string", Format: "uuid"},
+		})
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// queryParams lists the fields of a struct bound with ShouldBindQuery.
+func (g *generator) queryParams(t reflect.Type) []Parameter {
+	var params []Parameter
+	for f := range fields(t, "form") {
+		s := g.schemaFor(f.Type)
+		for _, opt := range strings.Split(f.opts, ",") {
+			if def, ok := strings.CutPrefix(opt, "default="); ok {
+				s.Default = parseDefault(s, def)
+			}
+		}
+		required := applyBinding(s, f.StructField)
+		params = append(params, Parameter{
+			Name:     f.name,
+			In:       "query",
+			Required: required,
+			Schema:   s,
+		})
+	}
+	return params
+}
+
+func parseDefault(s *Schema, v string) any {
+	if s.Type == "integer" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	}
+	return v
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,180 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	timeType = reflect.TypeFor[time.Time]()
+	uuidType = reflect.TypeFor[uuid.UUID]()
+)
+
+// schemaFor returns the schema of t. Named structs are added to the
+// components once and referenced.
+func (g *generator) schemaFor(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schemaFor(t.Elem())
+		if s.Ref != "" {
+			// Siblings of $ref are ignored in 3.0
+			return &Schema{AllOf: []*Schema{s}, Nullable: true}
+		}
+		s.Nullable = true
+		return s
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := schemaName(t)
+		if _, ok := g.schemas[name]; !ok {
+			// Reserve the name first so that recursive types terminate
+			g.schemas[name] = &Schema{}
+			*g.schemas[name] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for f := range fields(t, "json") {
+		prop := g.schemaFor(f.Type)
+		if applyBinding(prop, f.StructField) {
+			s.Required = append(s.Required, f.name)
+		}
+		s.Properties[f.name] = prop
+	}
+	return s
+}
+
+type field struct {
+	reflect.StructField
+	name string
+	opts string
+}
+
+// fields yields the exported fields of t named by the given tag, skipping
+// those tagged "-".
+func fields(t reflect.Type, tag string) func(yield func(field) bool) {
+	return func(yield func(field) bool) {
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(f.Tag.Get(tag), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			if !yield(field{StructField: f, name: name, opts: opts}) {
+				return
+			}
+		}
+	}
+}
+
+// applyBinding translates the validator rules of f's binding tag into schema
+// constraints and reports whether the field is required.
+func applyBinding(s *Schema, f reflect.StructField) bool {
+	target := s
+	if len(s.AllOf) > 0 {
+		target = s.AllOf[0]
+	}
+	required := false
+	for _, rule := range strings.Split(f.Tag.Get("binding"), ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "dive":
+			// Rules after dive apply to elements, which carry their own tags
+			return required
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				target.Enum = append(target.Enum, v)
+			}
+		case "gt", "gte", "min", "lte", "max":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			setBound(target, name, n)
+		}
+	}
+	return required
+}
+
+func setBound(s *Schema, rule string, n float64) {
+	lower := rule != "lte" && rule != "max"
+	count := int(n)
+	switch s.Type {
+	case "array":
+		if lower {
+			s.MinItems = &count
+		} else {
+			s.MaxItems = &count
+		}
+	case "string":
+		if lower {
+			s.MinLength = &count
+		} else {
+			s.MaxLength = &count
+		}
+	default:
+		if lower {
+			s.Minimum = &n
+			s.ExclusiveMinimum = rule == "gt"
+		} else {
+			s.Maximum = &n
+		}
+	}
+}
+
+// schemaName is the component name of a named type. Instances of generic
+// types are named after their type arguments, e.g. Page[Account] becomes
+// PageAccount.
+func schemaName(t reflect.Type) string {
+	name, args, ok := strings.Cut(t.Name(), "[")
+	if !ok {
+		return name
+	}
+	for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+		if i := strings.LastIndex(arg, "."); i >= 0 {
+			arg = arg[i+1:]
+		}
+		name += arg
+	}
+	return name
+}
@@ -0,0 +1,114 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type page[T any] struct {
+	Data []T `json:"data"`
+}
+
+type item struct {
+	ID        uuid.UUID  `json:"id" binding:"required"`
+	Amount    int64      `json:"amount" binding:"required,gt=0"`
+	Currency  string     `json:"currency" binding:"required,oneof=USD EUR"`
+	Tags      []string   `json:"tags" binding:"omitempty,min=1,max=3"`
+	Parent    *item      `json:"parent"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Internal  string     `json:"-"`
+}
+
+type listParams struct {
+	Limit  int32     `form:"limit,default=10" binding:"min=1,max=100"`
+	From   time.Time `form:"from" binding:"required"`
+	Cursor string    `form:"cursor"`
+	ID     uuid.UUID `form:"-"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	g := &generator{schemas: map[string]*Schema{}}
+
+	ref := g.schemaFor(reflect.TypeFor[page[item]]())
+	if ref.Ref != "#/components/schemas/pageitem" {
+		t.Fatalf("expected generic instance to be named after its argument, got %q", ref.Ref)
+	}
+
+	s, ok := g.schemas["item"]
+	if !ok {
+		t.Fatal("expected item to be added to the components")
+	}
+	if !reflect.DeepEqual(s.Required, []string{"id", "amount", "currency"}) {
+		t.Errorf("unexpected required fields %v", s.Required)
+	}
+	if _, ok := s.Properties["Internal"]; ok {
+		t.Error("expected fields tagged - to be skipped")
+	}
+
+	tests := []struct {
+		field string
+		check func(*Schema) bool
+	}{
+		{"id", func(s *Schema) bool { return s.Type == "string" && s.Format == "uuid" }},
+		{"amount", func(s *Schema) bool { return s.Format == "int64" && *s.Minimum == 0 && s.ExclusiveMinimum }},
+		{"currency", func(s *Schema) bool { return reflect.DeepEqual(s.Enum, []any{"USD", "EUR"}) }},
+		{"tags", func(s *Schema) bool { return s.Type == "array" && *s.MinItems == 1 && *s.MaxItems == 3 }},
+		{"parent", func(s *Schema) bool { return s.Nullable && s.AllOf[0].Ref == "#/components/schemas/item" }},
+		{"expires_at", func(s *Schema) bool { return s.Nullable && s.Format == "date-time" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			prop, ok := s.Properties[tt.field]
+			if !ok {
+				t.Fatalf("missing property %s", tt.field)
+			}
+			if !tt.check(prop) {
+				t.Errorf("unexpected schema %+v", prop)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	doc := Build(Spec{
+		Problem:     struct{}{},
+		ProblemType: "application/problem+json",
+		Routes: []Route{
+			{Method: http.MethodGet, Path: "/items/:id/children", OperationID: "listChildren",
+				Params: listParams{}, Status: http.StatusOK, Response: page[item]{}},
+			{Method: http.MethodGet, Path: "/healthz", OperationID: "health", Status: http.StatusOK, Public: true},
+		},
+	})
+
+	op, ok := doc.Operation(http.MethodGet, "/items/:id/children")
+	if !ok {
+		t.Fatal("expected operation at /items/{id}/children")
+	}
+	var names []string
+	for _, p := range op.Parameters {
+		names = append(names, p.In+":"+p.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"path:id", "query:limit", "query:from", "query:cursor"}) {
+		t.Errorf("unexpected parameters %v", names)
+	}
+	if op.Parameters[1].Schema.Default != int64(10) || !op.Parameters[2].Required {
+		t.Errorf("expected default and required to come from the tags, got %+v", op.Parameters)
+	}
+	for _, status := range []string{"200", "400", "401", "403", "429", "500"} {
+		if _, ok := op.Responses[status]; !ok {
+			t.Errorf("expected a %s response", status)
+		}
+	}
+
+	health, _ := doc.Operation(http.MethodGet, "/healthz")
+	if len(health.Security) != 1 || len(health.Security[0]) != 0 {
+		t.Errorf("expected public operation to clear security, got %v", health.Security)
+	}
+	if _, ok := health.Responses["401"]; ok {
+		t.Error("expected no 401 on a public operation")
+	}
+}
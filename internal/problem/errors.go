@@ -0,0 +1,63 @@
+package problem
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/idempotency"
+	"github.com/gabrielvieirabra/payments-ledger/internal/service"
+)
+
+type mapping struct {
+	err    error
+	status int
+	code   string
+	title  string
+}
+
+// mappings lists every error clients can act on. The first match wins, so
+// more specific errors come first.
+var mappings = []mapping{
+	{service.ErrAccountNotFound, http.StatusNotFound, "account_not_found", "Account not found"},
+	{service.ErrEntryNotFound, http.StatusNotFound, "entry_not_found", "Entry not found"},
+	{service.ErrTransactionNotFound, http.StatusNotFound, "transaction_not_found", "Transaction not found"},
+
+	{service.ErrSameAccount, http.StatusBadRequest, "same_account", "Same source and destination account"},
+	{service.ErrCurrencyMismatch, http.StatusBadRequest, "currency_mismatch", "Currency mismatch"},
+	{service.ErrUnbalancedJournal, http.StatusBadRequest, "unbalanced_journal", "Unbalanced journal"},
+	{service.ErrDuplicateLeg, http.StatusBadRequest, "duplicate_journal_leg", "Duplicate journal leg"},
+	{service.ErrAmountOutOfRange, http.StatusBadRequest, "amount_out_of_range", "Amount out of range"},
+	{service.ErrInvalidRange, http.StatusBadRequest, "invalid_range", "Invalid time range"},
+	{service.ErrTooManyBuckets, http.StatusBadRequest, "too_many_buckets", "Too many buckets"},
+	{domain.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor", "Invalid cursor"},
+
+	{service.ErrInsufficientBalance, http.StatusUnprocessableEntity, "insufficient_balance", "Insufficient balance"},
+	{service.ErrSystemAccount, http.StatusUnprocessableEntity, "system_account", "Operation not allowed on system account"},
+	{service.ErrAccountClosed, http.StatusUnprocessableEntity, "account_closed", "Account closed"},
+	{service.ErrNotReversible, http.StatusUnprocessableEntity, "not_reversible", "Transaction not reversible"},
+	{service.ErrReversalExceedsAmount, http.StatusUnprocessableEntity, "reversal_exceeds_amount", "Reversal exceeds amount"},
+
+	{service.ErrAccountFrozen, http.StatusLocked, "account_frozen", "Account frozen"},
+
+	{service.ErrAccountHasReferences, http.StatusConflict, "account_has_references", "Account has references"},
+	{service.ErrInvalidStatusTransition, http.StatusConflict, "invalid_status_transition", "Invalid status transition"},
+	{service.ErrAccountNotEmpty, http.StatusConflict, "account_not_empty", "Account not empty"},
+	{service.ErrAlreadyReversed, http.StatusConflict, "already_reversed", "Transaction already reversed"},
+	{service.ErrTransactionNotPending, http.StatusConflict, "transaction_not_pending", "Transaction not pending"},
+	{service.ErrAuthorizationExpired, http.StatusConflict, "authorization_expired", "Authorization expired"},
+	{idempotency.ErrReservationLost, http.StatusConflict, "idempotency_reservation_lost", "Idempotency reservation lost"},
+}
+
+// From maps a service error to its problem. The detail is the error message,
+// which may add context such as "source account not found".
+func From(err error) (Problem, bool) {
+	for _, m := range mappings {
+		if errors.Is(err, m.err) {
+			p := New(m.status, m.code, err.Error())
+			p.Title = m.title
+			return p, true
+		}
+	}
+	return Problem{}, false
+}
@@ -0,0 +1,134 @@
+// Package problem renders errors as RFC 7807 application/problem+json. Every
+// problem carries a stable machine-readable code; clients should branch on it
+// rather than on the human-readable title or detail.
+package problem
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/logging"
+)
+
+const (
+	ContentType = "application/problem+json"
+
+	// typeBase prefixes codes to form the problem type URI.
+	typeBase = "/problems/"
+)
+
+// Codes not tied to a service error.
+const (
+	CodeInternal          = "internal_error"
+	CodeInvalidRequest    = "invalid_request"
+	CodeValidationFailed  = "validation_failed"
+	CodeInvalidID         = "invalid_id"
+	CodeBodyTooLarge      = "body_too_large"
+	CodeUnauthorized      = "unauthorized"
+	CodePermissionDenied  = "permission_denied"
+	CodeRateLimited       = "rate_limited"
+	CodeUnavailable       = "service_unavailable"
+	CodeIdempotencyKey    = "invalid_idempotency_key"
+	CodeIdempotencyReused = "idempotency_key_reused"
+	CodeIdempotencyBusy   = "idempotency_key_in_flight"
+)
+
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	// Extensions are extra members specific to the problem type, e.g. the
+	// missing permission of a permission_denied problem.
+	Extensions map[string]any `json:"-"`
+}
+
+// FieldError describes one invalid field of a request.
+type FieldError struct {
+	Field  string `json:"field"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// New returns a problem whose title is the standard text of status.
+func New(status int, code, detail string) Problem {
+	return Problem{
+		Type:   typeBase + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// With returns a copy of p with an extension member added.
+func (p Problem) With(key string, value any) Problem {
+	ext := make(map[string]any, len(p.Extensions)+1)
+	for k, v := range p.Extensions {
+		ext[k] = v
+	}
+	ext[key] = value
+	p.Extensions = ext
+	return p
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type plain Problem
+	if len(p.Extensions) == 0 {
+		return json.Marshal(plain(p))
+	}
+
+	raw, err := json.Marshal(plain(p))
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[string]any, len(p.Extensions))
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+	// Standard members win over extensions with the same name
+	var standard map[string]any
+	if err := json.Unmarshal(raw, &standard); err != nil {
+		return nil, err
+	}
+	for k, v := range standard {
+		members[k] = v
+	}
+	return json.Marshal(members)
+}
+
+// Abort writes p as the response, filling in the request ID and instance.
+func Abort(c *gin.Context, p Problem) {
+	p.Instance = c.Request.URL.Path
+	p.RequestID = logging.RequestID(c.Request.Context())
+
+	c.Header("Content-Type", ContentType)
+	c.Status(p.Status)
+	body, err := json.Marshal(p)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "failed to encode problem", "error", err)
+		c.Abort()
+		return
+	}
+	_, _ = c.Writer.Write(body)
+	c.Abort()
+}
+
+// Respond maps err to a problem and writes it. Errors without a mapping are
+// logged with failureMsg and answered with a 500 whose detail is failureMsg,
+// so internals never leak to clients.
+func Respond(c *gin.Context, err error, failureMsg string) {
+	if p, ok := From(err); ok {
+		Abort(c, p)
+		return
+	}
+	slog.ErrorContext(c.Request.Context(), failureMsg, "error", err)
+	Abort(c, New(http.StatusInternalServerError, CodeInternal, failureMsg))
+}
@@ -0,0 +1,169 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/logging"
+	"github.com/gabrielvieirabra/payments-ledger/internal/service"
+)
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"not found", service.ErrAccountNotFound, http.StatusNotFound, "account_not_found"},
+		{"wrapped", fmt.Errorf("source account: %w", service.ErrAccountFrozen), http.StatusLocked, "account_frozen"},
+		{"business rule", service.ErrInsufficientBalance, http.StatusUnprocessableEntity, "insufficient_balance"},
+		{"conflict", service.ErrTransactionNotPending, http.StatusConflict, "transaction_not_pending"},
+		{"domain error", domain.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := From(tt.err)
+			if !ok {
+				t.Fatalf("expected a mapping for %v", tt.err)
+			}
+			if p.Status != tt.status || p.Code != tt.code {
+				t.Errorf("expected %d %s, got %d %s", tt.status, tt.code, p.Status, p.Code)
+			}
+			if p.Type != "/problems/"+tt.code {
+				t.Errorf("expected type to derive from code, got %q", p.Type)
+			}
+			if p.Detail != tt.err.Error() {
+				t.Errorf("expected detail %q, got %q", tt.err.Error(), p.Detail)
+			}
+		})
+	}
+
+	if _, ok := From(errors.New("connection reset")); ok {
+		t.Error("expected unknown errors to have no mapping")
+	}
+}
+
+func TestRespond(t *testing.T) {
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), "req-1"))
+		Respond(c, errors.New("connection reset"), "failed to get account")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("expected content type %q, got %q", ContentType, ct)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["code"] != CodeInternal || body["request_id"] != "req-1" || body["instance"] != "/" {
+		t.Errorf("unexpected body %v", body)
+	}
+	if strings.Contains(w.Body.String(), "connection reset") {
+		t.Error("expected internal error to stay out of the response")
+	}
+}
+
+func TestFromBinding(t *testing.T) {
+	router := gin.New()
+	var got Problem
+	router.POST("/", func(c *gin.Context) {
+		var req domain.CreateJournalRequest
+		got = FromBinding(c.ShouldBindJSON(&req))
+	})
+
+	tests := []struct {
+		name   string
+		body   string
+		code   string
+		fields map[string]string
+	}{
+		{
+			name: "missing legs",
+			body: `{}`,
+			code: CodeValidationFailed,
+			fields: map[string]string{
+				"legs": "required",
+			},
+		},
+		{
+			name: "invalid leg",
+			body: `{"legs":[{"account_id":"6f1e0c56-4f5e-4a0e-9d43-2f0f0b6f7a11","amount":5,"currency":"JPY"},{"amount":-5,"currency":"USD"}]}`,
+			code: CodeValidationFailed,
+			fields: map[string]string{
+				"legs[0].currency":   "oneof",
+				"legs[1].account_id": "required",
+			},
+		},
+		{
+			name: "wrong type",
+			body: `{"legs":"none"}`,
+			code: CodeValidationFailed,
+			fields: map[string]string{
+				"legs": "type",
+			},
+		},
+		{"empty body", ``, CodeInvalidRequest, nil},
+		{"malformed", `{"legs":`, CodeInvalidRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+
+			if got.Status != http.StatusBadRequest || got.Code != tt.code {
+				t.Fatalf("expected 400 %s, got %d %s", tt.code, got.Status, got.Code)
+			}
+			if len(got.Errors) != len(tt.fields) {
+				t.Fatalf("expected %d field errors, got %v", len(tt.fields), got.Errors)
+			}
+			for _, fe := range got.Errors {
+				if tt.fields[fe.Field] != fe.Code {
+					t.Errorf("unexpected field error %+v", fe)
+				}
+			}
+		})
+	}
+}
+
+func TestMarshalExtensions(t *testing.T) {
+	p := New(http.StatusForbidden, CodePermissionDenied, "permission denied").
+		With("permission", "accounts:admin").
+		With("code", "overridden")
+
+	raw, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(raw, &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["permission"] != "accounts:admin" {
+		t.Errorf("expected extension member, got %v", body)
+	}
+	if body["code"] != CodePermissionDenied {
+		t.Errorf("expected standard members to win over extensions, got %v", body["code"])
+	}
+	if body["status"] != float64(http.StatusForbidden) {
+		t.Errorf("expected status 403, got %v", body["status"])
+	}
+}
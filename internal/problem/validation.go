@@ -0,0 +1,116 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report fields by their JSON or query name rather than the Go field name
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return f.Name
+		})
+	}
+}
+
+// Validation answers a request that failed to bind, listing each invalid
+// field when the error allows it.
+func Validation(c *gin.Context, err error) {
+	Abort(c, FromBinding(err))
+}
+
+// FromBinding converts an error from ShouldBindJSON or ShouldBindQuery.
+func FromBinding(err error) Problem {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return New(http.StatusRequestEntityTooLarge, CodeBodyTooLarge, "request body too large")
+	}
+
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		p := New(http.StatusBadRequest, CodeValidationFailed, "request has invalid fields")
+		for _, fe := range verrs {
+			p.Errors = append(p.Errors, FieldError{
+				Field:  fieldPath(fe),
+				Code:   fe.Tag(),
+				Detail: fieldDetail(fe),
+			})
+		}
+		return p
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		p := New(http.StatusBadRequest, CodeValidationFailed, "request has invalid fields")
+		p.Errors = []FieldError{{
+			Field:  typeErr.Field,
+			Code:   "type",
+			Detail: fmt.Sprintf("must be a %s", typeErr.Type),
+		}}
+		return p
+	}
+
+	if errors.Is(err, io.EOF) {
+		return New(http.StatusBadRequest, CodeInvalidRequest, "request body is empty")
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return New(http.StatusBadRequest, CodeInvalidRequest, "request body is not valid JSON")
+	}
+	return New(http.StatusBadRequest, CodeInvalidRequest, err.Error())
+}
+
+// fieldPath drops the top-level struct name from the validator's namespace,
+// e.g. "CreateJournalRequest.legs[0].amount" becomes "legs[0].amount".
+func fieldPath(fe validator.FieldError) string {
+	_, path, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		return fe.Field()
+	}
+	return path
+}
+
+func fieldDetail(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "gte", "min":
+		if fe.Kind() == reflect.Slice {
+			return "must have at least " + fe.Param() + " items"
+		}
+		return "must be at least " + fe.Param()
+	case "lte", "max":
+		return "must be at most " + fe.Param()
+	case "len":
+		return "must have length " + fe.Param()
+	case "uuid", "uuid4":
+		return "must be a UUID"
+	}
+	if fe.Param() != "" {
+		return fmt.Sprintf("failed %s=%s", fe.Tag(), fe.Param())
+	}
+	return "failed " + fe.Tag()
+}
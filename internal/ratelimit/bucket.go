@@ -0,0 +1,172 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StorePostgres = "postgres"
+	StoreMemory   = "memory"
+)
+
+// Limit allows Requests per Per on average, with bursts of up to Requests.
+// The zero Limit is unlimited.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// ParseLimit parses limits written as "<requests>/<duration>", e.g. "100/1s"
+// or "600/1m". "0" and "off" mean unlimited.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "0" || strings.EqualFold(s, "off") {
+		return Limit{}, nil
+	}
+	requests, per, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<duration>", s)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: requests must be a non-negative integer", s)
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: duration must be positive", s)
+	}
+	return Limit{Requests: n, Per: d}, nil
+}
+
+func (l Limit) Unlimited() bool {
+	return l.Requests == 0
+}
+
+func (l Limit) String() string {
+	if l.Unlimited() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Per)
+}
+
+// tokensPerSecond is the refill rate.
+func (l Limit) tokensPerSecond() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// Bucket is the persisted state of one token bucket. A zero Bucket is full.
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Take refills b for the time elapsed since it was last updated and tries to
+// take one token from it, returning the new bucket state.
+func Take(b Bucket, limit Limit, now time.Time) (Bucket, Result) {
+	if limit.Unlimited() {
+		return b, Result{Allowed: true}
+	}
+
+	burst := float64(limit.Requests)
+	tokens := burst
+	if !b.UpdatedAt.IsZero() {
+		tokens = b.Tokens
+		if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+			tokens = math.Min(burst, tokens+elapsed.Seconds()*limit.tokensPerSecond())
+		} else {
+			// Clock went backwards (e.g. another replica's clock is ahead);
+			// keep the later timestamp so tokens are not minted twice
+			now = b.UpdatedAt
+		}
+	}
+
+	if tokens < 1 {
+		wait := (1 - tokens) / limit.tokensPerSecond()
+		return Bucket{Tokens: tokens, UpdatedAt: now}, Result{
+			RetryAfter: time.Duration(math.Ceil(wait * float64(time.Second))),
+		}
+	}
+
+	tokens--
+	return Bucket{Tokens: tokens, UpdatedAt: now}, Result{Allowed: true, Remaining: int(tokens)}
+}
+
+// Combine merges the results of taking from several buckets for one request:
+// it is allowed only if every bucket allows it.
+func Combine(a, b Result) Result {
+	return Result{
+		Allowed:    a.Allowed && b.Allowed,
+		Remaining:  min(a.Remaining, b.Remaining),
+		RetryAfter: max(a.RetryAfter, b.RetryAfter),
+	}
+}
+
+// Full reports whether b would have refilled completely by now, meaning it
+// can be dropped without changing any future decision.
+func Full(b Bucket, limit Limit, now time.Time) bool {
+	if limit.Unlimited() {
+		return true
+	}
+	missing := float64(limit.Requests) - b.Tokens
+	return now.Sub(b.UpdatedAt).Seconds()*limit.tokensPerSecond() >= missing
+}
+
+// Store holds token buckets by key. Stores backed by a shared database let
+// several replicas enforce one limit.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	// TakeAll takes one token from each of the distinct keys, or none when
+	// any of them is empty, so a rejected request costs nothing
+	TakeAll(ctx context.Context, keys []string, limit Limit) (Result, error)
+}
+
+// Policy is a default limit with overrides for individual routes, keyed by
+// method and route pattern, e.g. "POST /api/v1/transactions".
+type Policy struct {
+	Default Limit
+	Routes  map[string]Limit
+}
+
+// For returns the limit for a route and the bucket name it is counted in:
+// overridden routes get a bucket of their own, the rest share "default".
+func (p Policy) For(method, route string) (Limit, string) {
+	name := method + " " + route
+	if limit, ok := p.Routes[name]; ok {
+		return limit, name
+	}
+	return p.Default, "default"
+}
+
+// ParseRoutes parses comma-separated "<METHOD> <route>=<limit>" overrides,
+// e.g. "POST /api/v1/transactions=20/1s,POST /api/v1/journal=5/1s".
+func ParseRoutes(s string) (map[string]Limit, error) {
+	routes := make(map[string]Limit)
+	for item := range strings.SplitSeq(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		route, raw, ok := strings.Cut(item, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || !hasPath || path == "" {
+			return nil, fmt.Errorf("invalid route rate limit %q: expected <METHOD> <route>=<limit>", item)
+		}
+		limit, err := ParseLimit(raw)
+		if err != nil {
+			return nil, err
+		}
+		routes[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = limit
+	}
+	return routes, nil
+}
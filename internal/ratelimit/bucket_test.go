@@ -0,0 +1,162 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{"100/1s", Limit{Requests: 100, Per: time.Second}, false},
+		{"600/1m", Limit{Requests: 600, Per: time.Minute}, false},
+		{" 5/500ms ", Limit{Requests: 5, Per: 500 * time.Millisecond}, false},
+		{"0", Limit{}, false},
+		{"off", Limit{}, false},
+		{"100", Limit{}, true},
+		{"x/1s", Limit{}, true},
+		{"-1/1s", Limit{}, true},
+		{"10/0s", Limit{}, true},
+		{"10/soon", Limit{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLimit(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTake_BurstThenRefill(t *testing.T) {
+	limit := Limit{Requests: 3, Per: 3 * time.Second} // one token per second
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var b Bucket
+	var res Result
+	for i := range 3 {
+		b, res = Take(b, limit, now)
+		if !res.Allowed {
+			t.Fatalf("request %d: expected burst to be allowed", i)
+		}
+		if res.Remaining != 2-i {
+			t.Errorf("request %d: expected %d remaining, got %d", i, 2-i, res.Remaining)
+		}
+	}
+
+	b, res = Take(b, limit, now)
+	if res.Allowed {
+		t.Fatal("expected request beyond burst to be rejected")
+	}
+	if res.RetryAfter != time.Second {
+		t.Errorf("expected retry after 1s, got %s", res.RetryAfter)
+	}
+
+	b, res = Take(b, limit, now.Add(400*time.Millisecond))
+	if res.Allowed {
+		t.Fatal("expected request before refill to be rejected")
+	}
+	if res.RetryAfter != 600*time.Millisecond {
+		t.Errorf("expected retry after 600ms, got %s", res.RetryAfter)
+	}
+
+	_, res = Take(b, limit, now.Add(time.Second))
+	if !res.Allowed {
+		t.Fatal("expected request after refill to be allowed")
+	}
+}
+
+func TestTake_RefillIsCappedAtBurst(t *testing.T) {
+	limit := Limit{Requests: 2, Per: time.Second}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	b := Bucket{Tokens: 0, UpdatedAt: now}
+	b, res := Take(b, limit, now.Add(time.Hour))
+	if !res.Allowed || res.Remaining != 1 {
+		t.Fatalf("expected allowed with 1 remaining, got %+v", res)
+	}
+	if b.Tokens != 1 {
+		t.Errorf("expected 1 token left, got %v", b.Tokens)
+	}
+}
+
+func TestTake_ClockSkewDoesNotMintTokens(t *testing.T) {
+	limit := Limit{Requests: 1, Per: time.Second}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	b, _ := Take(Bucket{}, limit, now)
+	b, res := Take(b, limit, now.Add(-time.Minute))
+	if res.Allowed {
+		t.Fatal("expected request with an earlier clock to be rejected")
+	}
+	if !b.UpdatedAt.Equal(now) {
+		t.Errorf("expected bucket to keep the later timestamp, got %s", b.UpdatedAt)
+	}
+}
+
+func TestTake_Unlimited(t *testing.T) {
+	for range 100 {
+		if _, res := Take(Bucket{}, Limit{}, time.Now()); !res.Allowed {
+			t.Fatal("expected unlimited requests to be allowed")
+		}
+	}
+}
+
+func TestFull(t *testing.T) {
+	limit := Limit{Requests: 10, Per: 10 * time.Second}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := Bucket{Tokens: 5, UpdatedAt: now}
+
+	if Full(b, limit, now.Add(4*time.Second)) {
+		t.Error("expected bucket to still be refilling after 4s")
+	}
+	if !Full(b, limit, now.Add(5*time.Second)) {
+		t.Error("expected bucket to be full after 5s")
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes("post /api/v1/transactions=20/1s, POST /api/v1/journal=off,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 routes, got %v", routes)
+	}
+	if routes["POST /api/v1/transactions"] != (Limit{Requests: 20, Per: time.Second}) {
+		t.Errorf("unexpected transactions limit %v", routes["POST /api/v1/transactions"])
+	}
+	if !routes["POST /api/v1/journal"].Unlimited() {
+		t.Errorf("expected journal to be unlimited, got %v", routes["POST /api/v1/journal"])
+	}
+
+	for _, bad := range []string{"/api/v1/journal=1/1s", "POST /api/v1/journal", "POST /api/v1/journal=fast"} {
+		if _, err := ParseRoutes(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestPolicy_For(t *testing.T) {
+	policy := Policy{
+		Default: Limit{Requests: 100, Per: time.Second},
+		Routes:  map[string]Limit{"POST /api/v1/journal": {Requests: 5, Per: time.Second}},
+	}
+
+	limit, bucket := policy.For("POST", "/api/v1/journal")
+	if limit.Requests != 5 || bucket != "POST /api/v1/journal" {
+		t.Errorf("expected journal override, got %v in %q", limit, bucket)
+	}
+	limit, bucket = policy.For("GET", "/api/v1/journal")
+	if limit.Requests != 100 || bucket != "default" {
+		t.Errorf("expected default limit, got %v in %q", limit, bucket)
+	}
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const minPruneSize = 10000
+
+type memoryBucket struct {
+	Bucket
+	limit Limit
+}
+
+// MemoryStore keeps buckets in process. Limits are enforced per replica.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]memoryBucket
+	pruneAt int
+	now     func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]memoryBucket),
+		pruneAt: minPruneSize,
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	return s.TakeAll(ctx, []string{key}, limit)
+}
+
+func (s *MemoryStore) TakeAll(_ context.Context, keys []string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if len(s.buckets) >= s.pruneAt {
+		s.prune(now)
+	}
+
+	result := Result{Allowed: true, Remaining: limit.Requests}
+	buckets := make([]Bucket, len(keys))
+	for i, key := range keys {
+		var res Result
+		buckets[i], res = Take(s.buckets[key].Bucket, limit, now)
+		result = Combine(result, res)
+	}
+	if !result.Allowed {
+		return result, nil
+	}
+	for i, key := range keys {
+		s.buckets[key] = memoryBucket{Bucket: buckets[i], limit: limit}
+	}
+	return result, nil
+}
+
+// prune drops buckets that have refilled, since a missing bucket is full.
+func (s *MemoryStore) prune(now time.Time) {
+	for key, b := range s.buckets {
+		if Full(b.Bucket, b.limit, now) {
+			delete(s.buckets, key)
+		}
+	}
+	s.pruneAt = max(minPruneSize, 2*len(s.buckets))
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_KeysAreIndependent(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 1, Per: time.Second}
+	ctx := context.Background()
+
+	if res, _ := store.Take(ctx, "a", limit); !res.Allowed {
+		t.Fatal("expected first request for a to be allowed")
+	}
+	if res, _ := store.Take(ctx, "a", limit); res.Allowed {
+		t.Fatal("expected second request for a to be rejected")
+	}
+	if res, _ := store.Take(ctx, "b", limit); !res.Allowed {
+		t.Fatal("expected first request for b to be allowed")
+	}
+
+	now = now.Add(time.Second)
+	if res, _ := store.Take(ctx, "a", limit); !res.Allowed {
+		t.Fatal("expected a to be allowed after refill")
+	}
+}
+
+func TestMemoryStore_TakeAllIsAllOrNothing(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 1, Per: time.Second}
+	ctx := context.Background()
+
+	if res, _ := store.Take(ctx, "b", limit); !res.Allowed {
+		t.Fatal("expected first request for b to be allowed")
+	}
+	res, _ := store.TakeAll(ctx, []string{"a", "b"}, limit)
+	if res.Allowed {
+		t.Fatal("expected request on a and b to be rejected")
+	}
+	if res.RetryAfter != time.Second {
+		t.Errorf("expected to retry when b refills, got %s", res.RetryAfter)
+	}
+	if res, _ := store.Take(ctx, "a", limit); !res.Allowed {
+		t.Fatal("expected the rejected request to leave a untouched")
+	}
+}
+
+func TestMemoryStore_PrunesFullBuckets(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	store.pruneAt = 3
+	limit := Limit{Requests: 1, Per: time.Second}
+	ctx := context.Background()
+
+	store.Take(ctx, "a", limit)
+	store.Take(ctx, "b", limit)
+	now = now.Add(time.Second)
+	store.Take(ctx, "c", limit)
+	store.Take(ctx, "d", limit)
+
+	if len(store.buckets) != 2 {
+		t.Errorf("expected refilled buckets to be pruned, got %d buckets", len(store.buckets))
+	}
+	if res, _ := store.Take(ctx, "c", limit); res.Allowed {
+		t.Error("expected c to still be limited after pruning")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -15,6 +16,17 @@ import (
 
 var ErrAccountHasReferences = errors.New("account has existing entries or transactions")
 
+const accountColumns = `id, owner, balance, available_balance, overdraft_limit, unlimited_overdraft,
+	currency, status, is_system, created_at, updated_at`
+
+func scanAccount(row pgx.Row) (domain.Account, error) {
+	var acc domain.Account
+	err := row.Scan(&acc.ID, &acc.Owner, &acc.Balance, &acc.AvailableBalance, &acc.OverdraftLimit,
+		&acc.UnlimitedOverdraft, &acc.Currency, &acc.Status, &acc.IsSystem, &acc.CreatedAt, &acc.UpdatedAt)
+	acc.AvailableCredit = domain.AvailableCreditOf(acc)
+	return acc, err
+}
+
 type AccountRepository struct {
 	pool *pgxpool.Pool
 }
@@ -24,12 +36,11 @@ func NewAccountRepository(pool *pgxpool.Pool) *AccountRepository {
 }
 
 func (r *AccountRepository) Create(ctx context.Context, req domain.CreateAccountRequest) (domain.Account, error) {
-	var acc domain.Account
-	err := r.pool.QueryRow(ctx,
-		`INSERT INTO accounts (owner, currency) VALUES ($1, $2)
-		 RETURNING id, owner, balance, currency, created_at, updated_at`,
-		req.Owner, req.Currency,
-	).Scan(&acc.ID, &acc.Owner, &acc.Balance, &acc.Currency, &acc.CreatedAt, &acc.UpdatedAt)
+	acc, err := scanAccount(r.pool.QueryRow(ctx,
+		`INSERT INTO accounts (owner, currency, overdraft_limit) VALUES ($1, $2, $3)
+		 RETURNING `+accountColumns,
+		req.Owner, req.Currency, req.OverdraftLimit,
+	))
 	if err != nil {
 		return domain.Account{}, fmt.Errorf("create account: %w", err)
 	}
@@ -37,23 +48,40 @@ func (r *AccountRepository) Create(ctx context.Context, req domain.CreateAccount
 }
 
 func (r *AccountRepository) GetByID(ctx context.Context, id uuid.UUID) (domain.Account, error) {
-	var acc domain.Account
-	err := r.pool.QueryRow(ctx,
-		`SELECT id, owner, balance, currency, created_at, updated_at FROM accounts WHERE id = $1`,
+	acc, err := scanAccount(r.pool.QueryRow(ctx,
+		`SELECT `+accountColumns+` FROM accounts WHERE id = $1`,
 		id,
-	).Scan(&acc.ID, &acc.Owner, &acc.Balance, &acc.Currency, &acc.CreatedAt, &acc.UpdatedAt)
+	))
 	if err != nil {
 		return domain.Account{}, fmt.Errorf("get account: %w", err)
 	}
 	return acc, nil
 }
 
+// GetSystemAccount returns the external funding account of a currency that
+// postings on accountID use. Each currency is spread over several funding
+// accounts so that deposits and withdrawals do not all queue on one row lock;
+// a given account always maps to the same one.
+func (r *AccountRepository) GetSystemAccount(ctx context.Context, currency string, accountID uuid.UUID) (domain.Account, error) {
+	h := fnv.New32a()
+	h.Write(accountID[:])
+	acc, err := scanAccount(r.pool.QueryRow(ctx,
+		`SELECT `+accountColumns+` FROM accounts
+		 WHERE is_system AND currency = $1
+		   AND system_shard = $2 % (SELECT count(*) FROM accounts WHERE is_system AND currency = $1)`,
+		currency, int64(h.Sum32()),
+	))
+	if err != nil {
+		return domain.Account{}, fmt.Errorf("get system account: %w", err)
+	}
+	return acc, nil
+}
+
 func (r *AccountRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, id uuid.UUID) (domain.Account, error) {
-	var acc domain.Account
-	err := tx.QueryRow(ctx,
-		`SELECT id, owner, balance, currency, created_at, updated_at FROM accounts WHERE id = $1 FOR NO KEY UPDATE`,
+	acc, err := scanAccount(tx.QueryRow(ctx,
+		`SELECT `+accountColumns+` FROM accounts WHERE id = $1 FOR NO KEY UPDATE`,
 		id,
-	).Scan(&acc.ID, &acc.Owner, &acc.Balance, &acc.Currency, &acc.CreatedAt, &acc.UpdatedAt)
+	))
 	if err != nil {
 		return domain.Account{}, fmt.Errorf("get account for update: %w", err)
 	}
@@ -61,24 +89,59 @@ func (r *AccountRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, id
 }
 
 func (r *AccountRepository) UpdateBalance(ctx context.Context, tx pgx.Tx, id uuid.UUID, amount int64) (domain.Account, error) {
-	var acc domain.Account
-	err := tx.QueryRow(ctx,
-		`UPDATE accounts SET balance = balance + $1, updated_at = now() WHERE id = $2
-		 RETURNING id, owner, balance, currency, created_at, updated_at`,
+	acc, err := scanAccount(tx.QueryRow(ctx,
+		`UPDATE accounts SET balance = balance + $1, available_balance = available_balance + $1, updated_at = now()
+		 WHERE id = $2
+		 RETURNING `+accountColumns,
 		amount, id,
-	).Scan(&acc.ID, &acc.Owner, &acc.Balance, &acc.Currency, &acc.CreatedAt, &acc.UpdatedAt)
+	))
 	if err != nil {
 		return domain.Account{}, fmt.Errorf("update account balance: %w", err)
 	}
 	return acc, nil
 }
 
-func (r *AccountRepository) List(ctx context.Context, params domain.ListAccountsParams) ([]domain.Account, error) {
-	rows, err := r.pool.Query(ctx,
-		`SELECT id, owner, balance, currency, created_at, updated_at FROM accounts
-		 ORDER BY created_at DESC LIMIT $1 OFFSET $2`,
-		params.Limit, params.Offset,
-	)
+// UpdateAvailableBalance places (negative amount) or releases (positive amount)
+// a hold without touching the posted balance.
+func (r *AccountRepository) UpdateAvailableBalance(ctx context.Context, tx pgx.Tx, id uuid.UUID, amount int64) (domain.Account, error) {
+	acc, err := scanAccount(tx.QueryRow(ctx,
+		`UPDATE accounts SET available_balance = available_balance + $1, updated_at = now() WHERE id = $2
+		 RETURNING `+accountColumns,
+		amount, id,
+	))
+	if err != nil {
+		return domain.Account{}, fmt.Errorf("update account available balance: %w", err)
+	}
+	return acc, nil
+}
+
+func (r *AccountRepository) UpdateStatus(ctx context.Context, tx pgx.Tx, id uuid.UUID, status string) (domain.Account, error) {
+	acc, err := scanAccount(tx.QueryRow(ctx,
+		`UPDATE accounts SET status = $1, updated_at = now() WHERE id = $2
+		 RETURNING `+accountColumns,
+		status, id,
+	))
+	if err != nil {
+		return domain.Account{}, fmt.Errorf("update account status: %w", err)
+	}
+	return acc, nil
+}
+
+func (r *AccountRepository) UpdateLimits(ctx context.Context, tx pgx.Tx, id uuid.UUID, overdraftLimit int64, unlimited bool) (domain.Account, error) {
+	acc, err := scanAccount(tx.QueryRow(ctx,
+		`UPDATE accounts SET overdraft_limit = $1, unlimited_overdraft = $2, updated_at = now() WHERE id = $3
+		 RETURNING `+accountColumns,
+		overdraftLimit, unlimited, id,
+	))
+	if err != nil {
+		return domain.Account{}, fmt.Errorf("update account limits: %w", err)
+	}
+	return acc, nil
+}
+
+func (r *AccountRepository) List(ctx context.Context, params domain.ListAccountsParams, after *domain.Cursor) ([]domain.Account, error) {
+	clause, args := keyset("", nil, after, params.Limit, params.Offset)
+	rows, err := r.pool.Query(ctx, `SELECT `+accountColumns+` FROM accounts `+clause, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list accounts: %w", err)
 	}
@@ -86,8 +149,8 @@ func (r *AccountRepository) List(ctx context.Context, params domain.ListAccounts
 
 	var accounts []domain.Account
 	for rows.Next() {
-		var acc domain.Account
-		if err := rows.Scan(&acc.ID, &acc.Owner, &acc.Balance, &acc.Currency, &acc.CreatedAt, &acc.UpdatedAt); err != nil {
+		acc, err := scanAccount(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan account: %w", err)
 		}
 		accounts = append(accounts, acc)
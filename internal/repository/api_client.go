@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+)
+
+var (
+	ErrAPIClientNotFound = errors.New("api client not found")
+	ErrAPIClientExists   = errors.New("api client already exists")
+)
+
+const apiClientColumns = `id, name, key_prefix, role, created_at, revoked_at`
+
+func scanAPIClient(row pgx.Row) (domain.APIClient, error) {
+	var client domain.APIClient
+	err := row.Scan(&client.ID, &client.Name, &client.KeyPrefix, &client.Role, &client.CreatedAt, &client.RevokedAt)
+	return client, err
+}
+
+type APIClientRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAPIClientRepository(pool *pgxpool.Pool) *APIClientRepository {
+	return &APIClientRepository{pool: pool}
+}
+
+func (r *APIClientRepository) Create(ctx context.Context, name, role, keyPrefix, keyHash string) (domain.APIClient, error) {
+	client, err := scanAPIClient(r.pool.QueryRow(ctx,
+		`INSERT INTO api_clients (name, role, key_prefix, key_hash) VALUES ($1, $2, $3, $4)
+		 RETURNING `+apiClientColumns,
+		name, role, keyPrefix, keyHash,
+	))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.APIClient{}, ErrAPIClientExists
+		}
+		return domain.APIClient{}, fmt.Errorf("create api client: %w", err)
+	}
+	return client, nil
+}
+
+// GetByKeyHash resolves an active client from the hash of its API key.
+func (r *APIClientRepository) GetByKeyHash(ctx context.Context, keyHash string) (domain.APIClient, error) {
+	client, err := scanAPIClient(r.pool.QueryRow(ctx,
+		`SELECT `+apiClientColumns+` FROM api_clients WHERE key_hash = $1 AND revoked_at IS NULL`,
+		keyHash,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.APIClient{}, ErrAPIClientNotFound
+		}
+		return domain.APIClient{}, fmt.Errorf("get api client: %w", err)
+	}
+	return client, nil
+}
+
+func (r *APIClientRepository) List(ctx context.Context) ([]domain.APIClient, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+apiClientColumns+` FROM api_clients ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("list api clients: %w", err)
+	}
+	defer rows.Close()
+
+	var clients []domain.APIClient
+	for rows.Next() {
+		client, err := scanAPIClient(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan api client: %w", err)
+		}
+		clients = append(clients, client)
+	}
+	return clients, rows.Err()
+}
+
+func (r *APIClientRepository) Revoke(ctx context.Context, name string) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE api_clients SET revoked_at = now() WHERE name = $1 AND revoked_at IS NULL`,
+		name,
+	)
+	if err != nil {
+		return fmt.Errorf("revoke api client: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAPIClientNotFound
+	}
+	return nil
+}
+
+// GetByID returns an active client.
+func (r *APIClientRepository) GetByID(ctx context.Context, id uuid.UUID) (domain.APIClient, error) {
+	client, err := scanAPIClient(r.pool.QueryRow(ctx,
+		`SELECT `+apiClientColumns+` FROM api_clients WHERE id = $1 AND revoked_at IS NULL`,
+		id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.APIClient{}, ErrAPIClientNotFound
+		}
+		return domain.APIClient{}, fmt.Errorf("get api client: %w", err)
+	}
+	return client, nil
+}
+
+// AddSecret creates a new signing secret for the named client, alongside any
+// secrets it already has.
+func (r *APIClientRepository) AddSecret(ctx context.Context, name, secret string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO api_client_secrets (client_id, secret)
+		 SELECT id, $2 FROM api_clients WHERE name = $1 AND revoked_at IS NULL
+		 RETURNING id`,
+		name, secret,
+	).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrAPIClientNotFound
+		}
+		return uuid.Nil, fmt.Errorf("add api client secret: %w", err)
+	}
+	return id, nil
+}
+
+func (r *APIClientRepository) RevokeSecret(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE api_client_secrets SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("revoke api client secret: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAPIClientNotFound
+	}
+	return nil
+}
+
+// ActiveSecrets returns the signing secrets a client may currently use.
+func (r *APIClientRepository) ActiveSecrets(ctx context.Context, clientID uuid.UUID) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT secret FROM api_client_secrets WHERE client_id = $1 AND revoked_at IS NULL`,
+		clientID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list api client secrets: %w", err)
+	}
+	secrets, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("scan api client secret: %w", err)
+	}
+	return secrets, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -11,6 +12,14 @@ import (
 	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
 )
 
+const entryColumns = `id, account_id, transaction_id, amount, balance_after, created_at`
+
+func scanEntry(row pgx.Row) (domain.Entry, error) {
+	var entry domain.Entry
+	err := row.Scan(&entry.ID, &entry.AccountID, &entry.TransactionID, &entry.Amount, &entry.BalanceAfter, &entry.CreatedAt)
+	return entry, err
+}
+
 type EntryRepository struct {
 	pool *pgxpool.Pool
 }
@@ -19,13 +28,16 @@ func NewEntryRepository(pool *pgxpool.Pool) *EntryRepository {
 	return &EntryRepository{pool: pool}
 }
 
+// Create inserts an entry. Callers hold the account row lock, so stamping it
+// with clock_timestamp() instead of the transaction start time keeps entries
+// ordered the same way their balance_after values were computed.
 func (r *EntryRepository) Create(ctx context.Context, tx pgx.Tx, params domain.CreateEntryParams) (domain.Entry, error) {
-	var entry domain.Entry
-	err := tx.QueryRow(ctx,
-		`INSERT INTO entries (account_id, amount) VALUES ($1, $2)
-		 RETURNING id, account_id, amount, created_at`,
-		params.AccountID, params.Amount,
-	).Scan(&entry.ID, &entry.AccountID, &entry.Amount, &entry.CreatedAt)
+	entry, err := scanEntry(tx.QueryRow(ctx,
+		`INSERT INTO entries (account_id, transaction_id, amount, balance_after, created_at)
+		 VALUES ($1, $2, $3, $4, clock_timestamp())
+		 RETURNING `+entryColumns,
+		params.AccountID, params.TransactionID, params.Amount, params.BalanceAfter,
+	))
 	if err != nil {
 		return domain.Entry{}, fmt.Errorf("create entry: %w", err)
 	}
@@ -33,32 +45,101 @@ func (r *EntryRepository) Create(ctx context.Context, tx pgx.Tx, params domain.C
 }
 
 func (r *EntryRepository) GetByID(ctx context.Context, id uuid.UUID) (domain.Entry, error) {
-	var entry domain.Entry
-	err := r.pool.QueryRow(ctx,
-		`SELECT id, account_id, amount, created_at FROM entries WHERE id = $1`,
+	entry, err := scanEntry(r.pool.QueryRow(ctx,
+		`SELECT `+entryColumns+` FROM entries WHERE id = $1`,
 		id,
-	).Scan(&entry.ID, &entry.AccountID, &entry.Amount, &entry.CreatedAt)
+	))
 	if err != nil {
 		return domain.Entry{}, fmt.Errorf("get entry: %w", err)
 	}
 	return entry, nil
 }
 
-func (r *EntryRepository) ListByAccount(ctx context.Context, params domain.ListEntriesParams) ([]domain.Entry, error) {
+func (r *EntryRepository) ListByAccount(ctx context.Context, params domain.ListEntriesParams, after *domain.Cursor) ([]domain.Entry, error) {
+	clause, args := keyset("WHERE account_id = $1", []any{params.AccountID}, after, params.Limit, params.Offset)
+	rows, err := r.pool.Query(ctx, `SELECT `+entryColumns+` FROM entries `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list entries: %w", err)
+	}
+	return collectEntries(rows)
+}
+
+// Latest returns the most recent entry of an account.
+func (r *EntryRepository) Latest(ctx context.Context, accountID uuid.UUID) (domain.Entry, error) {
+	entry, err := scanEntry(r.pool.QueryRow(ctx,
+		`SELECT `+entryColumns+` FROM entries
+		 WHERE account_id = $1 ORDER BY created_at DESC, id DESC LIMIT 1`,
+		accountID,
+	))
+	if err != nil {
+		return domain.Entry{}, fmt.Errorf("get latest entry: %w", err)
+	}
+	return entry, nil
+}
+
+// ListByTransaction returns the legs of a transaction, debits first.
+func (r *EntryRepository) ListByTransaction(ctx context.Context, transactionID uuid.UUID) ([]domain.Entry, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT id, account_id, amount, created_at FROM entries
-		 WHERE account_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`,
-		params.AccountID, params.Limit, params.Offset,
+		`SELECT `+entryColumns+` FROM entries
+		 WHERE transaction_id = $1 ORDER BY amount, id`,
+		transactionID,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("list entries: %w", err)
+		return nil, fmt.Errorf("list transaction entries: %w", err)
 	}
+	return collectEntries(rows)
+}
+
+// SumUntil returns the balance of an account made of the entries posted
+// strictly before until.
+func (r *EntryRepository) SumUntil(ctx context.Context, accountID uuid.UUID, until time.Time) (int64, error) {
+	var balance int64
+	err := r.pool.QueryRow(ctx,
+		`SELECT COALESCE(SUM(amount), 0)::BIGINT FROM entries WHERE account_id = $1 AND created_at < $2`,
+		accountID, until,
+	).Scan(&balance)
+	if err != nil {
+		return 0, fmt.Errorf("sum entries: %w", err)
+	}
+	return balance, nil
+}
+
+// Movements returns debit and credit totals per UTC interval bucket within
+// [from, to). Buckets without entries are omitted.
+func (r *EntryRepository) Movements(ctx context.Context, accountID uuid.UUID, from, to time.Time, interval string) ([]domain.BalanceBucket, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT date_trunc($2, created_at AT TIME ZONE 'UTC') AS bucket,
+		        COALESCE(SUM(-amount) FILTER (WHERE amount < 0), 0)::BIGINT,
+		        COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0)::BIGINT
+		 FROM entries
+		 WHERE account_id = $1 AND created_at >= $3 AND created_at < $4
+		 GROUP BY bucket ORDER BY bucket`,
+		accountID, interval, from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate entries: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []domain.BalanceBucket
+	for rows.Next() {
+		var b domain.BalanceBucket
+		if err := rows.Scan(&b.Start, &b.TotalDebits, &b.TotalCredits); err != nil {
+			return nil, fmt.Errorf("scan bucket: %w", err)
+		}
+		b.Start = b.Start.UTC()
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+func collectEntries(rows pgx.Rows) ([]domain.Entry, error) {
 	defer rows.Close()
 
 	var entries []domain.Entry
 	for rows.Next() {
-		var entry domain.Entry
-		if err := rows.Scan(&entry.ID, &entry.AccountID, &entry.Amount, &entry.CreatedAt); err != nil {
+		entry, err := scanEntry(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan entry: %w", err)
 		}
 		entries = append(entries, entry)
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
@@ -13,22 +15,40 @@ import (
 
 var ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
 
+const idempotencyColumns = `id, client_id, idempotency_key, method, path, request_hash, status, status_code, response_body, locked_at, created_at, expires_at`
+
+func scanIdempotencyKey(row pgx.Row) (domain.IdempotencyKey, error) {
+	var ik domain.IdempotencyKey
+	var requestHash *string
+	var statusCode *int
+	err := row.Scan(&ik.ID, &ik.ClientID, &ik.IdempotencyKey, &ik.Method, &ik.Path, &requestHash, &ik.Status,
+		&statusCode, &ik.ResponseBody, &ik.LockedAt, &ik.CreatedAt, &ik.ExpiresAt)
+	if requestHash != nil {
+		ik.RequestHash = *requestHash
+	}
+	if statusCode != nil {
+		ik.StatusCode = *statusCode
+	}
+	return ik, err
+}
+
 type IdempotencyRepository struct {
 	pool *pgxpool.Pool
+	ttl  time.Duration
 }
 
-func NewIdempotencyRepository(pool *pgxpool.Pool) *IdempotencyRepository {
-	return &IdempotencyRepository{pool: pool}
+func NewIdempotencyRepository(pool *pgxpool.Pool, ttl time.Duration) *IdempotencyRepository {
+	return &IdempotencyRepository{pool: pool, ttl: ttl}
 }
 
-func (r *IdempotencyRepository) Find(ctx context.Context, key, method, path string) (domain.IdempotencyKey, error) {
-	var ik domain.IdempotencyKey
-	err := r.pool.QueryRow(ctx,
-		`SELECT id, idempotency_key, method, path, status_code, response_body, created_at, expires_at
+func (r *IdempotencyRepository) Find(ctx context.Context, clientID *uuid.UUID, key, method, path string) (domain.IdempotencyKey, error) {
+	ik, err := scanIdempotencyKey(r.pool.QueryRow(ctx,
+		`SELECT `+idempotencyColumns+`
 		 FROM idempotency_keys
-		 WHERE idempotency_key = $1 AND method = $2 AND path = $3 AND expires_at > now()`,
-		key, method, path,
-	).Scan(&ik.ID, &ik.IdempotencyKey, &ik.Method, &ik.Path, &ik.StatusCode, &ik.ResponseBody, &ik.CreatedAt, &ik.ExpiresAt)
+		 WHERE client_id IS NOT DISTINCT FROM $1 AND idempotency_key = $2 AND method = $3 AND path = $4
+		   AND expires_at > now()`,
+		clientID, key, method, path,
+	))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.IdempotencyKey{}, ErrIdempotencyKeyNotFound
@@ -38,15 +58,95 @@ func (r *IdempotencyRepository) Find(ctx context.Context, key, method, path stri
 	return ik, nil
 }
 
-func (r *IdempotencyRepository) Store(ctx context.Context, key, method, path string, statusCode int, responseBody []byte) error {
+// Reserve atomically claims a key as processing. It takes over rows that have
+// expired or whose reservation is older than lockTimeout, which is how keys
+// left behind by a crashed request are recovered. ok is false when the key is
+// held by another request or already completed.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, clientID *uuid.UUID, key, method, path, requestHash string, lockTimeout time.Duration) (domain.IdempotencyKey, bool, error) {
+	ik, err := scanIdempotencyKey(r.pool.QueryRow(ctx,
+		`INSERT INTO idempotency_keys (client_id, idempotency_key, method, path, request_hash, status, locked_at, expires_at)
+		 VALUES ($7, $1, $2, $3, $4, 'processing', now(), now() + make_interval(secs => $6))
+		 ON CONFLICT (client_id, idempotency_key, method, path) DO UPDATE
+		 SET request_hash = EXCLUDED.request_hash,
+		     status = 'processing',
+		     locked_at = now(),
+		     status_code = NULL,
+		     response_body = NULL,
+		     created_at = now(),
+		     expires_at = EXCLUDED.expires_at
+		 WHERE idempotency_keys.expires_at <= now()
+		    OR (idempotency_keys.status = 'processing'
+		        AND idempotency_keys.locked_at <= now() - make_interval(secs => $5))
+		 RETURNING `+idempotencyColumns,
+		key, method, path, requestHash, lockTimeout.Seconds(), r.ttl.Seconds(), clientID,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.IdempotencyKey{}, false, nil
+		}
+		return domain.IdempotencyKey{}, false, fmt.Errorf("reserve idempotency key: %w", err)
+	}
+	return ik, true, nil
+}
+
+// Complete stores the response for a reservation. It is a no-op if the
+// reservation was taken over after its lock timed out.
+func (r *IdempotencyRepository) Complete(ctx context.Context, reservation domain.IdempotencyKey, statusCode int, responseBody []byte) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE idempotency_keys
+		 SET status = 'completed', status_code = $3, response_body = $4, locked_at = NULL
+		 WHERE id = $1 AND status = 'processing' AND locked_at = $2`,
+		reservation.ID, reservation.LockedAt, statusCode, responseBody,
+	)
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// CompleteTx is Complete within the caller's transaction. It reports false if
+// the reservation was taken over, so that the caller can roll back.
+func (r *IdempotencyRepository) CompleteTx(ctx context.Context, tx pgx.Tx, reservation domain.IdempotencyKey, statusCode int, responseBody []byte) (bool, error) {
+	tag, err := tx.Exec(ctx,
+		`UPDATE idempotency_keys
+		 SET status = 'completed', status_code = $3, response_body = $4, locked_at = NULL
+		 WHERE id = $1 AND status = 'processing' AND locked_at = $2`,
+		reservation.ID, reservation.LockedAt, statusCode, responseBody,
+	)
+	if err != nil {
+		return false, fmt.Errorf("complete idempotency key: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Release drops a reservation so the key can be retried.
+func (r *IdempotencyRepository) Release(ctx context.Context, reservation domain.IdempotencyKey) error {
 	_, err := r.pool.Exec(ctx,
-		`INSERT INTO idempotency_keys (idempotency_key, method, path, status_code, response_body)
-		 VALUES ($1, $2, $3, $4, $5)
-		 ON CONFLICT (idempotency_key, method, path) DO NOTHING`,
-		key, method, path, statusCode, responseBody,
+		`DELETE FROM idempotency_keys WHERE id = $1 AND status = 'processing' AND locked_at = $2`,
+		reservation.ID, reservation.LockedAt,
 	)
 	if err != nil {
-		return fmt.Errorf("store idempotency key: %w", err)
+		return fmt.Errorf("release idempotency key: %w", err)
 	}
 	return nil
 }
+
+// DeleteExpired removes up to limit expired keys. Rows locked by a concurrent
+// Reserve taking them over are skipped.
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM idempotency_keys
+		 WHERE id IN (
+		     SELECT id FROM idempotency_keys
+		     WHERE expires_at <= now()
+		     ORDER BY expires_at
+		     LIMIT $1
+		     FOR UPDATE SKIP LOCKED
+		 )`,
+		limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
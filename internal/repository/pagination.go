@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+)
+
+// keyset appends the ORDER BY/LIMIT clause of a list query, resuming after
+// the cursor when one is given and falling back to OFFSET otherwise. One
+// extra row is requested so callers can tell whether a next page exists.
+func keyset(where string, args []any, after *domain.Cursor, limit, offset int32) (string, []any) {
+	if after != nil {
+		if where == "" {
+			where = "WHERE"
+		} else {
+			where += " AND"
+		}
+		where += fmt.Sprintf(" (created_at, id) < ($%d, $%d)", len(args)+1, len(args)+2)
+		args = append(args, after.CreatedAt, after.ID)
+		offset = 0
+	}
+
+	query := fmt.Sprintf("%s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", where, len(args)+1, len(args)+2)
+	return query, append(args, limit+1, offset)
+}
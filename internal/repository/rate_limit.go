@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/ratelimit"
+)
+
+// RateLimitRepository stores token buckets in Postgres so that every replica
+// enforces the same limits.
+type RateLimitRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRateLimitRepository(pool *pgxpool.Pool) *RateLimitRepository {
+	return &RateLimitRepository{pool: pool}
+}
+
+// Take locks the bucket row, so concurrent requests for one key are
+// serialised, and applies the same bucket math as the memory store using the
+// database clock.
+func (r *RateLimitRepository) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return r.TakeAll(ctx, []string{key}, limit)
+}
+
+// TakeAll locks every bucket row in key order, so that concurrent requests
+// sharing keys cannot deadlock, and only writes them back if all allow the
+// request.
+func (r *RateLimitRepository) TakeAll(ctx context.Context, keys []string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	if limit.Unlimited() {
+		return ratelimit.Result{Allowed: true}, nil
+	}
+	keys = slices.Sorted(slices.Values(keys))
+
+	result := ratelimit.Result{Allowed: true, Remaining: limit.Requests}
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		buckets := make([]ratelimit.Bucket, len(keys))
+		for i, key := range keys {
+			// Create missing buckets full, so that concurrent first requests for a
+			// key queue up on the row lock below instead of overwriting each other
+			_, err := tx.Exec(ctx,
+				`INSERT INTO rate_limit_buckets (key, tokens, updated_at, full_at)
+				 VALUES ($1, $2, clock_timestamp(), clock_timestamp())
+				 ON CONFLICT (key) DO NOTHING`,
+				key, float64(limit.Requests),
+			)
+			if err != nil {
+				return err
+			}
+
+			var now time.Time
+			err = tx.QueryRow(ctx,
+				`SELECT tokens, updated_at, clock_timestamp() FROM rate_limit_buckets WHERE key = $1 FOR UPDATE`,
+				key,
+			).Scan(&buckets[i].Tokens, &buckets[i].UpdatedAt, &now)
+			if err != nil {
+				return err
+			}
+
+			var res ratelimit.Result
+			buckets[i], res = ratelimit.Take(buckets[i], limit, now)
+			result = ratelimit.Combine(result, res)
+		}
+		if !result.Allowed {
+			return nil
+		}
+
+		for i, key := range keys {
+			refill := time.Duration((float64(limit.Requests) - buckets[i].Tokens) / float64(limit.Requests) * float64(limit.Per))
+			_, err := tx.Exec(ctx,
+				`UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3, full_at = $4 WHERE key = $1`,
+				key, buckets[i].Tokens, buckets[i].UpdatedAt, buckets[i].UpdatedAt.Add(refill),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ratelimit.Result{}, fmt.Errorf("take rate limit token: %w", err)
+	}
+	return result, nil
+}
+
+// DeleteFull removes up to limit buckets that have refilled.
+func (r *RateLimitRepository) DeleteFull(ctx context.Context, limit int) (int64, error) {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM rate_limit_buckets
+		 WHERE key IN (
+		     SELECT key FROM rate_limit_buckets
+		     WHERE full_at <= now()
+		     LIMIT $1
+		     FOR UPDATE SKIP LOCKED
+		 )`,
+		limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("delete full rate limit buckets: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
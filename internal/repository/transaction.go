@@ -11,6 +11,18 @@ import (
 	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
 )
 
+const transactionColumns = `id, from_account_id, to_account_id, amount, kind, status, expires_at, reversal_of,
+	(SELECT COALESCE(SUM(r.amount), 0)::BIGINT FROM transactions r WHERE r.reversal_of = transactions.id),
+	client_id, created_at`
+
+func scanTransaction(row pgx.Row) (domain.Transaction, error) {
+	var txn domain.Transaction
+	err := row.Scan(&txn.ID, &txn.FromAccountID, &txn.ToAccountID, &txn.Amount, &txn.Kind, &txn.Status,
+		&txn.ExpiresAt, &txn.ReversalOf, &txn.ReversedAmount, &txn.ClientID, &txn.CreatedAt)
+	txn.ReversalStatus = domain.ReversalStatusOf(txn.Amount, txn.ReversedAmount)
+	return txn, err
+}
+
 type TransactionRepository struct {
 	pool *pgxpool.Pool
 }
@@ -19,13 +31,14 @@ func NewTransactionRepository(pool *pgxpool.Pool) *TransactionRepository {
 	return &TransactionRepository{pool: pool}
 }
 
-func (r *TransactionRepository) Create(ctx context.Context, tx pgx.Tx, fromID, toID uuid.UUID, amount int64) (domain.Transaction, error) {
-	var txn domain.Transaction
-	err := tx.QueryRow(ctx,
-		`INSERT INTO transactions (from_account_id, to_account_id, amount) VALUES ($1, $2, $3)
-		 RETURNING id, from_account_id, to_account_id, amount, created_at`,
-		fromID, toID, amount,
-	).Scan(&txn.ID, &txn.FromAccountID, &txn.ToAccountID, &txn.Amount, &txn.CreatedAt)
+func (r *TransactionRepository) Create(ctx context.Context, tx pgx.Tx, params domain.CreateTransactionParams) (domain.Transaction, error) {
+	txn, err := scanTransaction(tx.QueryRow(ctx,
+		`INSERT INTO transactions (from_account_id, to_account_id, amount, kind, status, expires_at, reversal_of, client_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 RETURNING `+transactionColumns,
+		params.FromAccountID, params.ToAccountID, params.Amount, params.Kind, params.Status, params.ExpiresAt,
+		params.ReversalOf, params.ClientID,
+	))
 	if err != nil {
 		return domain.Transaction{}, fmt.Errorf("create transaction: %w", err)
 	}
@@ -33,33 +46,107 @@ func (r *TransactionRepository) Create(ctx context.Context, tx pgx.Tx, fromID, t
 }
 
 func (r *TransactionRepository) GetByID(ctx context.Context, id uuid.UUID) (domain.Transaction, error) {
-	var txn domain.Transaction
-	err := r.pool.QueryRow(ctx,
-		`SELECT id, from_account_id, to_account_id, amount, created_at FROM transactions WHERE id = $1`,
+	txn, err := scanTransaction(r.pool.QueryRow(ctx,
+		`SELECT `+transactionColumns+` FROM transactions WHERE id = $1`,
 		id,
-	).Scan(&txn.ID, &txn.FromAccountID, &txn.ToAccountID, &txn.Amount, &txn.CreatedAt)
+	))
 	if err != nil {
 		return domain.Transaction{}, fmt.Errorf("get transaction: %w", err)
 	}
 	return txn, nil
 }
 
-func (r *TransactionRepository) ListByAccount(ctx context.Context, accountID uuid.UUID, limit, offset int32) ([]domain.Transaction, error) {
+// GetByIDForUpdate locks a transaction row and returns it. The row is read
+// again after the lock is granted so that the reversed amount includes
+// reversals committed while waiting for it.
+func (r *TransactionRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, id uuid.UUID) (domain.Transaction, error) {
+	var lockedID uuid.UUID
+	err := tx.QueryRow(ctx,
+		`SELECT id FROM transactions WHERE id = $1 FOR NO KEY UPDATE`,
+		id,
+	).Scan(&lockedID)
+	if err != nil {
+		return domain.Transaction{}, fmt.Errorf("lock transaction: %w", err)
+	}
+
+	txn, err := scanTransaction(tx.QueryRow(ctx,
+		`SELECT `+transactionColumns+` FROM transactions WHERE id = $1`,
+		id,
+	))
+	if err != nil {
+		return domain.Transaction{}, fmt.Errorf("get transaction for update: %w", err)
+	}
+	return txn, nil
+}
+
+func (r *TransactionRepository) UpdateStatus(ctx context.Context, tx pgx.Tx, id uuid.UUID, status string) (domain.Transaction, error) {
+	txn, err := scanTransaction(tx.QueryRow(ctx,
+		`UPDATE transactions SET status = $1 WHERE id = $2
+		 RETURNING `+transactionColumns,
+		status, id,
+	))
+	if err != nil {
+		return domain.Transaction{}, fmt.Errorf("update transaction status: %w", err)
+	}
+	return txn, nil
+}
+
+// ListExpiredPending returns pending authorizations past their expiry, oldest first.
+func (r *TransactionRepository) ListExpiredPending(ctx context.Context, limit int32) ([]domain.Transaction, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT id, from_account_id, to_account_id, amount, created_at FROM transactions
-		 WHERE from_account_id = $1 OR to_account_id = $1
-		 ORDER BY created_at DESC LIMIT $2 OFFSET $3`,
-		accountID, limit, offset,
+		`SELECT `+transactionColumns+` FROM transactions
+		 WHERE status = 'pending' AND expires_at <= now()
+		 ORDER BY expires_at LIMIT $1`,
+		limit,
 	)
+	if err != nil {
+		return nil, fmt.Errorf("list expired authorizations: %w", err)
+	}
+	return collectTransactions(rows)
+}
+
+// ListByAccount lists the transactions an account takes part in. An OR over
+// from_account_id, to_account_id and entries would defeat the keyset
+// indexes, so each is walked separately, reading at most one page from each
+// index, and the branches are merged. Journal legs have no source or
+// destination and are found through their entries.
+func (r *TransactionRepository) ListByAccount(ctx context.Context, params domain.ListTransactionsParams, after *domain.Cursor) ([]domain.Transaction, error) {
+	args := []any{params.AccountID}
+	var resume string
+	offset := params.Offset
+	if after != nil {
+		resume = " AND (created_at, id) < ($2, $3)"
+		args = append(args, after.CreatedAt, after.ID)
+		offset = 0
+	}
+	n := len(args)
+	order := " ORDER BY created_at DESC, id DESC"
+	branch := order + fmt.Sprintf(" LIMIT $%d", n+1)
+
+	query := `SELECT ` + transactionColumns + ` FROM transactions WHERE id IN (
+		(SELECT id FROM transactions WHERE from_account_id = $1` + resume + branch + `)
+		UNION ALL
+		(SELECT id FROM transactions WHERE to_account_id = $1` + resume + branch + `)
+		UNION ALL
+		(SELECT id FROM transactions WHERE from_account_id IS NULL
+		    AND id IN (SELECT transaction_id FROM entries WHERE account_id = $1)` + resume + branch + `)
+	)` + order + fmt.Sprintf(" LIMIT $%d OFFSET $%d", n+2, n+3)
+	args = append(args, params.Limit+1+offset, params.Limit+1, offset)
+
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list transactions: %w", err)
 	}
+	return collectTransactions(rows)
+}
+
+func collectTransactions(rows pgx.Rows) ([]domain.Transaction, error) {
 	defer rows.Close()
 
 	var transactions []domain.Transaction
 	for rows.Next() {
-		var txn domain.Transaction
-		if err := rows.Scan(&txn.ID, &txn.FromAccountID, &txn.ToAccountID, &txn.Amount, &txn.CreatedAt); err != nil {
+		txn, err := scanTransaction(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan transaction: %w", err)
 		}
 		transactions = append(transactions, txn)
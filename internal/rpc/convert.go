@@ -0,0 +1,86 @@
+package rpc
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	ledgerv1 "github.com/gabrielvieirabra/payments-ledger/api/proto/ledger/v1"
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+)
+
+func accountToProto(a domain.Account) *ledgerv1.Account {
+	return &ledgerv1.Account{
+		Id:                 a.ID.String(),
+		Owner:              a.Owner,
+		Balance:            a.Balance,
+		AvailableBalance:   a.AvailableBalance,
+		OverdraftLimit:     a.OverdraftLimit,
+		UnlimitedOverdraft: a.UnlimitedOverdraft,
+		AvailableCredit:    a.AvailableCredit,
+		Currency:           a.Currency,
+		Status:             a.Status,
+		IsSystem:           a.IsSystem,
+		CreatedAt:          timestamppb.New(a.CreatedAt),
+		UpdatedAt:          timestamppb.New(a.UpdatedAt),
+	}
+}
+
+func transactionToProto(t domain.Transaction) *ledgerv1.Transaction {
+	return &ledgerv1.Transaction{
+		Id:             t.ID.String(),
+		FromAccountId:  optionalID(t.FromAccountID),
+		ToAccountId:    optionalID(t.ToAccountID),
+		Amount:         t.Amount,
+		Kind:           t.Kind,
+		Status:         t.Status,
+		ExpiresAt:      optionalTime(t.ExpiresAt),
+		ReversalOf:     optionalID(t.ReversalOf),
+		ReversedAmount: t.ReversedAmount,
+		ReversalStatus: t.ReversalStatus,
+		ClientId:       optionalID(t.ClientID),
+		CreatedAt:      timestamppb.New(t.CreatedAt),
+	}
+}
+
+func entryToProto(e domain.Entry) *ledgerv1.Entry {
+	return &ledgerv1.Entry{
+		Id:            e.ID.String(),
+		AccountId:     e.AccountID.String(),
+		TransactionId: optionalID(e.TransactionID),
+		Amount:        e.Amount,
+		BalanceAfter:  e.BalanceAfter,
+		CreatedAt:     timestamppb.New(e.CreatedAt),
+	}
+}
+
+func transferToProto(r domain.TransactionResult) *ledgerv1.TransferResponse {
+	resp := &ledgerv1.TransferResponse{
+		Transaction: transactionToProto(r.Transaction),
+		FromAccount: accountToProto(r.FromAccount),
+		ToAccount:   accountToProto(r.ToAccount),
+	}
+	if r.FromEntry != nil {
+		resp.FromEntry = entryToProto(*r.FromEntry)
+	}
+	if r.ToEntry != nil {
+		resp.ToEntry = entryToProto(*r.ToEntry)
+	}
+	return resp
+}
+
+func optionalID(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	s := id.String()
+	return &s
+}
+
+func optionalTime(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
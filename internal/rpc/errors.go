@@ -0,0 +1,106 @@
+package rpc
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
+)
+
+// errorDomain is the ErrorInfo domain of every error returned by the server.
+const errorDomain = "payments-ledger"
+
+// statusCodes maps the HTTP status of a problem to its gRPC code. Business
+// rule violations (409, 422, 423) are all FailedPrecondition; clients tell
+// them apart by the ErrorInfo reason.
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusConflict:              codes.FailedPrecondition,
+	http.StatusRequestEntityTooLarge: codes.InvalidArgument,
+	http.StatusUnprocessableEntity:   codes.FailedPrecondition,
+	http.StatusLocked:                codes.FailedPrecondition,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+}
+
+// retryableCodes are problems the same call may succeed on later.
+var retryableCodes = map[string]codes.Code{
+	problem.CodeIdempotencyBusy:    codes.Aborted,
+	"idempotency_reservation_lost": codes.Aborted,
+}
+
+// respond converts an error returned by a service into a status, like
+// problem.Respond does for HTTP. Statuses pass through; unknown errors are
+// logged with failureMsg and answered with Internal.
+func respond(ctx context.Context, err error, failureMsg string) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if p, ok := problem.From(err); ok {
+		return statusOf(p)
+	}
+	slog.ErrorContext(ctx, failureMsg, "error", err)
+	return statusOf(problem.New(http.StatusInternalServerError, problem.CodeInternal, failureMsg))
+}
+
+// statusOf builds the status of p, carrying its code as ErrorInfo reason and
+// its field errors as BadRequest violations.
+func statusOf(p problem.Problem) error {
+	code, ok := retryableCodes[p.Code]
+	if !ok {
+		code, ok = statusCodes[p.Status]
+	}
+	if !ok {
+		code = codes.Internal
+	}
+
+	st := status.New(code, p.Detail)
+	info := &errdetails.ErrorInfo{Reason: p.Code, Domain: errorDomain}
+	if len(p.Errors) == 0 {
+		if withDetails, err := st.WithDetails(info); err == nil {
+			st = withDetails
+		}
+		return st.Err()
+	}
+
+	violations := &errdetails.BadRequest{}
+	for _, fe := range p.Errors {
+		violations.FieldViolations = append(violations.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fe.Field,
+			Description: fe.Detail,
+		})
+	}
+	if withDetails, err := st.WithDetails(info, violations); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// validate applies the binding rules of a domain request, the same ones the
+// REST handlers enforce.
+func validate(req any) error {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return statusOf(problem.FromBinding(err))
+	}
+	return nil
+}
+
+func parseID(field, value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		p := problem.New(http.StatusBadRequest, problem.CodeInvalidID, "invalid "+field)
+		p.Errors = []problem.FieldError{{Field: field, Code: "uuid", Detail: "must be a UUID"}}
+		return uuid.Nil, statusOf(p)
+	}
+	return id, nil
+}
@@ -0,0 +1,163 @@
+package rpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/auth"
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/idempotency"
+	"github.com/gabrielvieirabra/payments-ledger/internal/metrics"
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
+	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
+)
+
+const (
+	IdempotencyKeyMetadata     = "idempotency-key"
+	IdempotentReplayedMetadata = "idempotent-replayed"
+
+	// idempotencyMethod stands in for the HTTP method of stored keys, so
+	// that gRPC calls never match REST ones
+	idempotencyMethod = "GRPC"
+)
+
+// idempotencyGuard runs each idempotency key at most once and replays the
+// stored result, mirroring the REST middleware. Results are stored as the
+// JSON of the domain value, as the services record them in-transaction.
+type idempotencyGuard struct {
+	store       idempotency.Store
+	lockTimeout time.Duration
+}
+
+// idempotent runs fn under the call's idempotency key, if any. successStatus
+// is the HTTP status the equivalent REST call answers with.
+func idempotent[T any](ctx context.Context, g idempotencyGuard, req proto.Message, successStatus int, fn func(context.Context) (T, error)) (T, error) {
+	var zero T
+	key := firstMetadata(ctx, IdempotencyKeyMetadata)
+	if key == "" || g.store == nil {
+		return fn(ctx)
+	}
+	if len(key) > 255 {
+		return zero, statusOf(problem.New(http.StatusBadRequest, problem.CodeIdempotencyKey, "idempotency key must be at most 255 characters"))
+	}
+
+	method, _ := grpc.Method(ctx)
+	requestHash, err := fingerprint(method, req)
+	if err != nil {
+		return zero, respond(ctx, err, "failed to hash request")
+	}
+
+	clientID := auth.ClientID(ctx)
+	reservation, reserved, err := g.store.Reserve(ctx, clientID, key, idempotencyMethod, method, requestHash, g.lockTimeout)
+	if err != nil {
+		return zero, respond(ctx, err, "failed to reserve idempotency key")
+	}
+	if !reserved {
+		return replay[T](ctx, g.store, clientID, key, method, requestHash)
+	}
+	metrics.IdempotencyRequests.WithLabelValues("miss").Inc()
+
+	// Outcomes are recorded even if the client hangs up: the money may
+	// already have moved
+	storeCtx := context.WithoutCancel(ctx)
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		if err := g.store.Release(storeCtx, reservation); err != nil {
+			slog.ErrorContext(ctx, "failed to release idempotency key", "key", key, "error", err)
+		}
+	}()
+
+	var scope *idempotency.Scope
+	if recorder, ok := g.store.(idempotency.TxRecorder); ok {
+		scope = idempotency.NewScope(reservation, recorder)
+		ctx = idempotency.WithScope(ctx, scope)
+	}
+	idempotency.Expect(ctx, successStatus)
+
+	result, err := fn(ctx)
+	if err != nil {
+		return zero, err
+	}
+	if scope != nil && scope.IsCommitted() {
+		completed = true
+		return result, nil
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to encode idempotent response", "key", key, "error", err)
+		return result, nil
+	}
+	if err := g.store.Complete(storeCtx, reservation, successStatus, body); err != nil {
+		slog.ErrorContext(ctx, "failed to store idempotency key", "key", key, "error", err)
+		return result, nil
+	}
+	completed = true
+	return result, nil
+}
+
+func replay[T any](ctx context.Context, store idempotency.Store, clientID *uuid.UUID, key, method, requestHash string) (T, error) {
+	var result T
+	busy := problem.New(http.StatusConflict, problem.CodeIdempotencyBusy, "request with this idempotency key is being processed, retry later")
+
+	cached, err := store.Find(ctx, clientID, key, idempotencyMethod, method)
+	if err != nil {
+		if errors.Is(err, repository.ErrIdempotencyKeyNotFound) {
+			// Released or expired since Reserve; the client can retry
+			metrics.IdempotencyRequests.WithLabelValues("in_flight").Inc()
+			return result, statusOf(busy)
+		}
+		return result, respond(ctx, err, "failed to check idempotency key")
+	}
+
+	if cached.RequestHash != "" && cached.RequestHash != requestHash {
+		metrics.IdempotencyRequests.WithLabelValues("mismatch").Inc()
+		return result, statusOf(problem.New(http.StatusUnprocessableEntity, problem.CodeIdempotencyReused,
+			"idempotency key was already used with a different request"))
+	}
+	if cached.Status == domain.IdempotencyStatusProcessing {
+		metrics.IdempotencyRequests.WithLabelValues("in_flight").Inc()
+		return result, statusOf(busy)
+	}
+
+	if err := json.Unmarshal(cached.ResponseBody, &result); err != nil {
+		return result, respond(ctx, err, "failed to decode idempotent response")
+	}
+	metrics.IdempotencyRequests.WithLabelValues("hit").Inc()
+	_ = grpc.SetHeader(ctx, metadata.Pairs(IdempotentReplayedMetadata, "true"))
+	return result, nil
+}
+
+// fingerprint hashes the method and the deterministic encoding of req.
+func fingerprint(method string, req proto.Message) (string, error) {
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,218 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+
+	ledgerv1 "github.com/gabrielvieirabra/payments-ledger/api/proto/ledger/v1"
+	"github.com/gabrielvieirabra/payments-ledger/internal/auth"
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/logging"
+	"github.com/gabrielvieirabra/payments-ledger/internal/metrics"
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
+	"github.com/gabrielvieirabra/payments-ledger/internal/ratelimit"
+	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
+)
+
+const RequestIDMetadata = "x-request-id"
+
+// methodPermissions lists the permission every method requires, as the
+// router does for REST routes. Methods missing here are refused.
+var methodPermissions = map[string]auth.Permission{
+	ledgerv1.LedgerService_CreateAccount_FullMethodName:    auth.PermAccountsWrite,
+	ledgerv1.LedgerService_GetAccount_FullMethodName:       auth.PermAccountsRead,
+	ledgerv1.LedgerService_Transfer_FullMethodName:         auth.PermTransfersWrite,
+	ledgerv1.LedgerService_ListEntries_FullMethodName:      auth.PermTransactionsRead,
+	ledgerv1.LedgerService_ListTransactions_FullMethodName: auth.PermTransactionsRead,
+}
+
+// publicServices are served without authentication or rate limiting, like
+// the REST health checks: probes and tooling carry no API key.
+var publicServices = []string{
+	healthpb.Health_ServiceDesc.ServiceName,
+	reflectionv1.ServerReflection_ServiceDesc.ServiceName,
+	reflectionv1alpha.ServerReflection_ServiceDesc.ServiceName,
+}
+
+func isPublic(fullMethod string) bool {
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return slices.Contains(publicServices, service)
+}
+
+// ClientResolver looks up an active API client by the hash of its key.
+type ClientResolver interface {
+	GetByKeyHash(ctx context.Context, keyHash string) (domain.APIClient, error)
+}
+
+// RequestID accepts the caller's x-request-id, or generates one, and echoes
+// it in the response header.
+func RequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id := firstMetadata(ctx, RequestIDMetadata)
+		if !logging.ValidRequestID(id) {
+			id = uuid.NewString()
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, id))
+		return handler(logging.WithRequestID(ctx, id), req)
+	}
+}
+
+// AccessLog logs one record per call and records its metrics.
+func AccessLog() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		elapsed := time.Since(start)
+
+		code := status.Code(err)
+		metrics.GRPCRequests.WithLabelValues(info.FullMethod, code.String()).Inc()
+		metrics.GRPCRequestDuration.WithLabelValues(info.FullMethod).Observe(elapsed.Seconds())
+
+		level := slog.LevelInfo
+		switch code {
+		case codes.OK:
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+			level = slog.LevelError
+		default:
+			level = slog.LevelWarn
+		}
+		attrs := []slog.Attr{
+			slog.String("method", info.FullMethod),
+			slog.String("code", code.String()),
+			slog.Duration("latency", elapsed),
+		}
+		if p, ok := peer.FromContext(ctx); ok {
+			attrs = append(attrs, slog.String("client_ip", p.Addr.String()))
+		}
+		if id := auth.ClientID(ctx); id != nil {
+			attrs = append(attrs, slog.String("client_id", id.String()))
+		}
+		slog.LogAttrs(ctx, level, "rpc", attrs...)
+		return resp, err
+	}
+}
+
+// Recovery turns panics into Internal errors and logs them with their stack.
+func Recovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.ErrorContext(ctx, "panic recovered", "error", r, "stack", string(debug.Stack()))
+				err = statusOf(problem.New(http.StatusInternalServerError, problem.CodeInternal, "internal error"))
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// Authenticate requires an "authorization: Bearer <api key>" metadata entry
+// and a role granting the method's permission.
+func Authenticate(resolver ClientResolver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if isPublic(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		scheme, key, ok := strings.Cut(firstMetadata(ctx, "authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || key == "" {
+			return nil, unauthenticated("missing api key")
+		}
+
+		client, err := resolver.GetByKeyHash(ctx, auth.HashKey(key))
+		if err != nil {
+			if errors.Is(err, repository.ErrAPIClientNotFound) {
+				return nil, unauthenticated("invalid api key")
+			}
+			return nil, respond(ctx, err, "failed to authenticate client")
+		}
+
+		perm, ok := methodPermissions[info.FullMethod]
+		if !ok || !auth.Allowed(client.Role, perm) {
+			return nil, statusOf(problem.New(http.StatusForbidden, problem.CodePermissionDenied, "permission denied"))
+		}
+		return handler(auth.WithClient(ctx, client), req)
+	}
+}
+
+func unauthenticated(msg string) error {
+	return statusOf(problem.New(http.StatusUnauthorized, problem.CodeUnauthorized, msg))
+}
+
+// rateLimitMethod stands in for the HTTP method of rate limit routes, so
+// that gRPC methods never match REST overrides.
+const rateLimitMethod = "GRPC"
+
+// RateLimit throttles each API client, or each peer address when
+// authentication is off. Methods use the client's shared REST bucket unless
+// the policy overrides "GRPC <full method>". Requests with a source account
+// also take from that account's bucket, shared with REST. Like the REST
+// limiter it fails open.
+func RateLimit(store ratelimit.Store, policy ratelimit.Policy, accountLimit ratelimit.Limit) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if isPublic(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		limit, bucket := policy.For(rateLimitMethod, info.FullMethod)
+
+		var caller string
+		if id := auth.ClientID(ctx); id != nil {
+			caller = "client:" + id.String()
+		} else if p, ok := peer.FromContext(ctx); ok {
+			host, _, err := net.SplitHostPort(p.Addr.String())
+			if err != nil {
+				host = p.Addr.String()
+			}
+			caller = "ip:" + host
+		}
+		if err := takeToken(ctx, store, caller+":"+bucket, limit); err != nil {
+			return nil, err
+		}
+
+		// Key on the parsed id so that every spelling shares the REST bucket;
+		// malformed ids are left to the handler to reject
+		if src, ok := req.(interface{ GetFromAccountId() string }); ok {
+			if id, err := uuid.Parse(src.GetFromAccountId()); err == nil {
+				if err := takeToken(ctx, store, "account:"+id.String(), accountLimit); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func takeToken(ctx context.Context, store ratelimit.Store, key string, limit ratelimit.Limit) error {
+	if limit.Unlimited() {
+		return nil
+	}
+	res, err := store.Take(ctx, key, limit)
+	if err != nil {
+		// Fail open: an unavailable limiter should not take payments down
+		slog.ErrorContext(ctx, "failed to check rate limit", "key", key, "error", err)
+		return nil
+	}
+	if !res.Allowed {
+		retryAfter := strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds())))
+		_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter))
+		return statusOf(problem.New(http.StatusTooManyRequests, problem.CodeRateLimited, "rate limit exceeded"))
+	}
+	return nil
+}
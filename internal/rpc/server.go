@@ -0,0 +1,213 @@
+// Package rpc serves the ledger over gRPC. It is a thin layer over the same
+// services as the REST handlers and shares their validation rules, API keys,
+// idempotency keys and error codes.
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	ledgerv1 "github.com/gabrielvieirabra/payments-ledger/api/proto/ledger/v1"
+	"github.com/gabrielvieirabra/payments-ledger/internal/auth"
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/idempotency"
+	"github.com/gabrielvieirabra/payments-ledger/internal/problem"
+	"github.com/gabrielvieirabra/payments-ledger/internal/ratelimit"
+	"github.com/gabrielvieirabra/payments-ledger/internal/service"
+)
+
+// defaultPageSize matches the default limit of the REST list endpoints.
+const defaultPageSize = 10
+
+// Options selects the interceptors installed by NewServer.
+type Options struct {
+	// Clients authenticates calls; nil turns authentication off
+	Clients ClientResolver
+	// RateLimits enables rate limiting; nil turns it off
+	RateLimits   ratelimit.Store
+	RatePolicy   ratelimit.Policy
+	AccountLimit ratelimit.Limit
+}
+
+// NewServer returns a gRPC server exposing ledger, the standard health
+// service and server reflection. Calls are traced, logged and counted. The
+// health server is returned so that callers can report NOT_SERVING before
+// stopping.
+func NewServer(ledger *LedgerServer, opts Options) (*grpc.Server, *health.Server) {
+	// Recovery runs inside the access log so that panics are logged as Internal
+	interceptors := []grpc.UnaryServerInterceptor{RequestID(), AccessLog(), Recovery()}
+	if opts.Clients != nil {
+		interceptors = append(interceptors, Authenticate(opts.Clients))
+	}
+	if opts.RateLimits != nil {
+		interceptors = append(interceptors, RateLimit(opts.RateLimits, opts.RatePolicy, opts.AccountLimit))
+	}
+
+	srv := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(interceptors...),
+	)
+	ledgerv1.RegisterLedgerServiceServer(srv, ledger)
+	healthSrv := health.NewServer()
+	healthpb.RegisterHealthServer(srv, healthSrv)
+	reflection.Register(srv)
+	return srv, healthSrv
+}
+
+type LedgerServer struct {
+	ledgerv1.UnimplementedLedgerServiceServer
+
+	accounts     *service.AccountService
+	entries      *service.EntryService
+	transactions *service.TransactionService
+	idempotency  idempotencyGuard
+}
+
+func NewLedgerServer(
+	accounts *service.AccountService,
+	entries *service.EntryService,
+	transactions *service.TransactionService,
+	store idempotency.Store,
+	lockTimeout time.Duration,
+) *LedgerServer {
+	return &LedgerServer{
+		accounts:     accounts,
+		entries:      entries,
+		transactions: transactions,
+		idempotency:  idempotencyGuard{store: store, lockTimeout: lockTimeout},
+	}
+}
+
+func (s *LedgerServer) CreateAccount(ctx context.Context, req *ledgerv1.CreateAccountRequest) (*ledgerv1.Account, error) {
+	create := domain.CreateAccountRequest{
+		Owner:          req.GetOwner(),
+		Currency:       req.GetCurrency(),
+		OverdraftLimit: req.GetOverdraftLimit(),
+	}
+	if err := validate(create); err != nil {
+		return nil, err
+	}
+	// As on REST, granting an overdraft limit needs the permission that
+	// changing it does
+	if create.OverdraftLimit > 0 {
+		if client, ok := auth.ClientFromContext(ctx); ok && !auth.Allowed(client.Role, auth.PermAccountsAdmin) {
+			return nil, statusOf(problem.New(http.StatusForbidden, problem.CodePermissionDenied, "permission denied"))
+		}
+	}
+
+	acc, err := idempotent(ctx, s.idempotency, req, http.StatusCreated, func(ctx context.Context) (domain.Account, error) {
+		return s.accounts.Create(ctx, create)
+	})
+	if err != nil {
+		return nil, respond(ctx, err, "failed to create account")
+	}
+	return accountToProto(acc), nil
+}
+
+func (s *LedgerServer) GetAccount(ctx context.Context, req *ledgerv1.GetAccountRequest) (*ledgerv1.Account, error) {
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	acc, err := s.accounts.GetByID(ctx, id)
+	if err != nil {
+		return nil, respond(ctx, err, "failed to get account")
+	}
+	return accountToProto(acc), nil
+}
+
+func (s *LedgerServer) Transfer(ctx context.Context, req *ledgerv1.TransferRequest) (*ledgerv1.TransferResponse, error) {
+	from, err := parseID("from_account_id", req.GetFromAccountId())
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseID("to_account_id", req.GetToAccountId())
+	if err != nil {
+		return nil, err
+	}
+	transfer := domain.CreateTransactionRequest{
+		FromAccountID: from,
+		ToAccountID:   to,
+		Amount:        req.GetAmount(),
+		Currency:      req.GetCurrency(),
+		Mode:          req.GetMode(),
+	}
+	if err := validate(transfer); err != nil {
+		return nil, err
+	}
+
+	result, err := idempotent(ctx, s.idempotency, req, http.StatusCreated, func(ctx context.Context) (domain.TransactionResult, error) {
+		return s.transactions.Transfer(ctx, transfer)
+	})
+	if err != nil {
+		return nil, respond(ctx, err, "failed to process transfer")
+	}
+	return transferToProto(result), nil
+}
+
+func (s *LedgerServer) ListEntries(ctx context.Context, req *ledgerv1.ListEntriesRequest) (*ledgerv1.ListEntriesResponse, error) {
+	accountID, err := parseID("account_id", req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+	params := domain.ListEntriesParams{
+		AccountID: accountID,
+		Limit:     pageSize(req.GetLimit()),
+		Cursor:    req.GetCursor(),
+	}
+	if err := validate(params); err != nil {
+		return nil, err
+	}
+
+	page, err := s.entries.ListByAccount(ctx, params)
+	if err != nil {
+		return nil, respond(ctx, err, "failed to list entries")
+	}
+
+	resp := &ledgerv1.ListEntriesResponse{NextCursor: page.NextCursor}
+	for _, e := range page.Data {
+		resp.Entries = append(resp.Entries, entryToProto(e))
+	}
+	return resp, nil
+}
+
+func (s *LedgerServer) ListTransactions(ctx context.Context, req *ledgerv1.ListTransactionsRequest) (*ledgerv1.ListTransactionsResponse, error) {
+	accountID, err := parseID("account_id", req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+	params := domain.ListTransactionsParams{
+		AccountID: accountID,
+		Limit:     pageSize(req.GetLimit()),
+		Cursor:    req.GetCursor(),
+	}
+	if err := validate(params); err != nil {
+		return nil, err
+	}
+
+	page, err := s.transactions.ListByAccount(ctx, params)
+	if err != nil {
+		return nil, respond(ctx, err, "failed to list transactions")
+	}
+
+	resp := &ledgerv1.ListTransactionsResponse{NextCursor: page.NextCursor}
+	for _, t := range page.Data {
+		resp.Transactions = append(resp.Transactions, transactionToProto(t))
+	}
+	return resp, nil
+}
+
+func pageSize(limit int32) int32 {
+	if limit == 0 {
+		return defaultPageSize
+	}
+	return limit
+}
@@ -0,0 +1,264 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	ledgerv1 "github.com/gabrielvieirabra/payments-ledger/api/proto/ledger/v1"
+	"github.com/gabrielvieirabra/payments-ledger/internal/auth"
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/ratelimit"
+	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
+	"github.com/gabrielvieirabra/payments-ledger/internal/service"
+)
+
+type roleResolver struct{}
+
+// GetByKeyHash treats the API key as the role name, with one client per role.
+func (roleResolver) GetByKeyHash(_ context.Context, keyHash string) (domain.APIClient, error) {
+	for _, role := range []string{auth.RoleViewer, auth.RoleProcessor, auth.RoleOperator} {
+		if auth.HashKey(role) == keyHash {
+			return domain.APIClient{ID: uuid.NewSHA1(uuid.Nil, []byte(role)), Name: role, Role: role}, nil
+		}
+	}
+	return domain.APIClient{}, repository.ErrAPIClientNotFound
+}
+
+// newTestClient serves a LedgerServer without services: calls must stop
+// before reaching a repository.
+func newTestClient(t *testing.T) ledgerv1.LedgerServiceClient {
+	t.Helper()
+	return ledgerv1.NewLedgerServiceClient(newTestConn(t))
+}
+
+func newTestConn(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+	conn, _ := serve(t, Options{Clients: roleResolver{}})
+	return conn
+}
+
+func serve(t *testing.T, opts Options) (*grpc.ClientConn, *health.Server) {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv, healthSrv := NewServer(NewLedgerServer(nil, nil, nil, nil, 0), opts)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn, healthSrv
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+key)
+}
+
+func TestServer_EveryMethodHasPermission(t *testing.T) {
+	for _, m := range ledgerv1.LedgerService_ServiceDesc.Methods {
+		method := "/" + ledgerv1.LedgerService_ServiceDesc.ServiceName + "/" + m.MethodName
+		if _, ok := methodPermissions[method]; !ok {
+			t.Errorf("method %s has no entry in methodPermissions", method)
+		}
+	}
+}
+
+func TestServer_Authentication(t *testing.T) {
+	client := newTestClient(t)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		code codes.Code
+	}{
+		{"missing key", context.Background(), codes.Unauthenticated},
+		{"unknown key", withKey("nobody"), codes.Unauthenticated},
+		{"missing permission", withKey(auth.RoleViewer), codes.PermissionDenied},
+		// Permitted calls stop at validation
+		{"permitted", withKey(auth.RoleProcessor), codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Transfer(tt.ctx, &ledgerv1.TransferRequest{})
+			if code := status.Code(err); code != tt.code {
+				t.Errorf("expected %s, got %s (%v)", tt.code, code, err)
+			}
+		})
+	}
+}
+
+func TestServer_PublicServices(t *testing.T) {
+	conn := newTestConn(t)
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("expected health check without a key to pass, got %v", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("expected SERVING, got %s", resp.GetStatus())
+	}
+
+	stream, err := reflectionv1.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stream.Send(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Errorf("expected reflection without a key to pass, got %v", err)
+	}
+}
+
+func TestServer_HealthShutdown(t *testing.T) {
+	conn, healthSrv := serve(t, Options{})
+	healthSrv.Shutdown()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected NOT_SERVING after shutdown, got %s", resp.GetStatus())
+	}
+}
+
+func TestServer_RateLimitRoutes(t *testing.T) {
+	conn, _ := serve(t, Options{
+		Clients:    roleResolver{},
+		RateLimits: ratelimit.NewMemoryStore(),
+		RatePolicy: ratelimit.Policy{Routes: map[string]ratelimit.Limit{
+			"GRPC " + ledgerv1.LedgerService_Transfer_FullMethodName: {Requests: 1, Per: time.Minute},
+		}},
+	})
+	client := ledgerv1.NewLedgerServiceClient(conn)
+	ctx := withKey(auth.RoleProcessor)
+
+	// Calls stop at validation once past the limiter
+	if _, err := client.Transfer(ctx, &ledgerv1.TransferRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected first transfer to pass the limiter, got %v", err)
+	}
+	if _, err := client.Transfer(ctx, &ledgerv1.TransferRequest{}); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected second transfer to be limited, got %v", err)
+	}
+	if _, err := client.GetAccount(ctx, &ledgerv1.GetAccountRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected other methods to keep the default limit, got %v", err)
+	}
+}
+
+func TestServer_OverdraftLimitRequiresAdmin(t *testing.T) {
+	client := newTestClient(t)
+
+	_, err := client.CreateAccount(withKey(auth.RoleProcessor), &ledgerv1.CreateAccountRequest{
+		Owner:          "alice",
+		Currency:       "USD",
+		OverdraftLimit: 1000,
+	})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied, got %s (%v)", code, err)
+	}
+}
+
+func TestServer_Validation(t *testing.T) {
+	client := newTestClient(t)
+	ctx := withKey(auth.RoleOperator)
+
+	_, err := client.Transfer(ctx, &ledgerv1.TransferRequest{
+		FromAccountId: uuid.NewString(),
+		ToAccountId:   uuid.NewString(),
+		Amount:        -5,
+		Currency:      "JPY",
+	})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %s", st.Code())
+	}
+
+	fields := map[string]bool{}
+	var reason string
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			reason = d.Reason
+		case *errdetails.BadRequest:
+			for _, v := range d.FieldViolations {
+				fields[v.Field] = true
+			}
+		}
+	}
+	if reason != "validation_failed" {
+		t.Errorf("expected validation_failed reason, got %q", reason)
+	}
+	if !fields["amount"] || !fields["currency"] {
+		t.Errorf("expected amount and currency violations, got %v", fields)
+	}
+
+	_, err = client.GetAccount(ctx, &ledgerv1.GetAccountRequest{Id: "42"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a malformed id, got %v", err)
+	}
+}
+
+func TestRespond(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		{"not found", service.ErrAccountNotFound, codes.NotFound, "account_not_found"},
+		{"business rule", fmt.Errorf("debit: %w", service.ErrInsufficientBalance), codes.FailedPrecondition, "insufficient_balance"},
+		{"frozen", service.ErrAccountFrozen, codes.FailedPrecondition, "account_frozen"},
+		{"bad input", service.ErrSameAccount, codes.InvalidArgument, "same_account"},
+		{"unknown", errors.New("connection reset"), codes.Internal, "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(respond(context.Background(), tt.err, "failed to process transfer"))
+			if st.Code() != tt.code {
+				t.Errorf("expected %s, got %s", tt.code, st.Code())
+			}
+			if len(st.Details()) == 0 {
+				t.Fatal("expected ErrorInfo details")
+			}
+			info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+			if !ok || info.Reason != tt.reason || info.Domain != errorDomain {
+				t.Errorf("expected reason %s, got %v", tt.reason, st.Details()[0])
+			}
+			if tt.code == codes.Internal && st.Message() != "failed to process transfer" {
+				t.Errorf("expected internal error to be hidden, got %q", st.Message())
+			}
+		})
+	}
+
+	busy := status.Error(codes.Aborted, "busy")
+	if got := respond(context.Background(), busy, "unused"); got != busy {
+		t.Errorf("expected statuses to pass through, got %v", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -15,8 +16,20 @@ import (
 var (
 	ErrAccountNotFound      = errors.New("account not found")
 	ErrAccountHasReferences = errors.New("account has existing entries or transactions")
+	ErrAccountFrozen        = errors.New("account is frozen")
+	ErrAccountClosed        = errors.New("account is closed")
+
+	ErrInvalidStatusTransition = errors.New("invalid account status transition")
+	ErrAccountNotEmpty         = errors.New("account balance must be zero to close it")
 )
 
+// statusTransitions lists the statuses each account status may move to.
+// Closing is final.
+var statusTransitions = map[string][]string{
+	domain.AccountStatusActive: {domain.AccountStatusFrozen, domain.AccountStatusClosed},
+	domain.AccountStatusFrozen: {domain.AccountStatusActive},
+}
+
 type AccountService struct {
 	repo *repository.AccountRepository
 }
@@ -40,12 +53,122 @@ func (s *AccountService) GetByID(ctx context.Context, id uuid.UUID) (domain.Acco
 	return acc, nil
 }
 
-func (s *AccountService) List(ctx context.Context, params domain.ListAccountsParams) ([]domain.Account, error) {
-	return s.repo.List(ctx, params)
+func (s *AccountService) List(ctx context.Context, params domain.ListAccountsParams) (domain.Page[domain.Account], error) {
+	after, err := parseCursor(params.Cursor)
+	if err != nil {
+		return domain.Page[domain.Account]{}, err
+	}
+
+	accounts, err := s.repo.List(ctx, params, after)
+	if err != nil {
+		return domain.Page[domain.Account]{}, err
+	}
+	return domain.NewPage(accounts, params.Limit, domain.Account.Cursor), nil
+}
+
+func (s *AccountService) UpdateStatus(ctx context.Context, id uuid.UUID, status string) (domain.Account, error) {
+	tx, err := s.repo.Pool().Begin(ctx)
+	if err != nil {
+		return domain.Account{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	// Lock the account so no transfer can change its balance while closing
+	acc, err := s.repo.GetByIDForUpdate(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Account{}, ErrAccountNotFound
+		}
+		return domain.Account{}, err
+	}
+	if acc.IsSystem {
+		return domain.Account{}, ErrSystemAccount
+	}
+	if acc.Status == status {
+		return acc, nil
+	}
+	if !slices.Contains(statusTransitions[acc.Status], status) {
+		return domain.Account{}, fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, acc.Status, status)
+	}
+	// Pending holds keep the available balance away from the posted one
+	if status == domain.AccountStatusClosed && (acc.Balance != 0 || acc.AvailableBalance != 0) {
+		return domain.Account{}, ErrAccountNotEmpty
+	}
+
+	updated, err := s.repo.UpdateStatus(ctx, tx, id, status)
+	if err != nil {
+		return domain.Account{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Account{}, fmt.Errorf("commit transaction: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *AccountService) UpdateLimits(ctx context.Context, id uuid.UUID, req domain.UpdateAccountLimitsRequest) (domain.Account, error) {
+	tx, err := s.repo.Pool().Begin(ctx)
+	if err != nil {
+		return domain.Account{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	acc, err := s.repo.GetByIDForUpdate(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Account{}, ErrAccountNotFound
+		}
+		return domain.Account{}, err
+	}
+	if acc.IsSystem {
+		return domain.Account{}, ErrSystemAccount
+	}
+	if acc.Status == domain.AccountStatusClosed {
+		return domain.Account{}, ErrAccountClosed
+	}
+
+	// Lowering the limit below the current usage only affects future debits
+	limit, unlimited := acc.OverdraftLimit, acc.UnlimitedOverdraft
+	if req.OverdraftLimit != nil {
+		limit = *req.OverdraftLimit
+	}
+	if req.UnlimitedOverdraft != nil {
+		unlimited = *req.UnlimitedOverdraft
+	}
+
+	updated, err := s.repo.UpdateLimits(ctx, tx, id, limit, unlimited)
+	if err != nil {
+		return domain.Account{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Account{}, fmt.Errorf("commit transaction: %w", err)
+	}
+	return updated, nil
+}
+
+// checkPostable rejects postings on accounts that are frozen or closed. The
+// role ("source", "destination", ...) prefixes the error message.
+func checkPostable(acc domain.Account, role string) error {
+	switch acc.Status {
+	case domain.AccountStatusFrozen:
+		return fmt.Errorf("%s %w", role, ErrAccountFrozen)
+	case domain.AccountStatusClosed:
+		return fmt.Errorf("%s %w", role, ErrAccountClosed)
+	}
+	return nil
 }
 
 func (s *AccountService) Delete(ctx context.Context, id uuid.UUID) error {
-	err := s.repo.Delete(ctx, id)
+	acc, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if acc.IsSystem {
+		return ErrSystemAccount
+	}
+
+	err = s.repo.Delete(ctx, id)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ErrAccountNotFound
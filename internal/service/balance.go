@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
+)
+
+// maxHistoryBuckets bounds the size of a balance history response.
+const maxHistoryBuckets = 1000
+
+var (
+	ErrInvalidRange   = errors.New("from must be before to")
+	ErrTooManyBuckets = errors.New("requested range has too many buckets, use a larger interval")
+)
+
+type BalanceService struct {
+	accountRepo *repository.AccountRepository
+	entryRepo   *repository.EntryRepository
+}
+
+func NewBalanceService(accountRepo *repository.AccountRepository, entryRepo *repository.EntryRepository) *BalanceService {
+	return &BalanceService{accountRepo: accountRepo, entryRepo: entryRepo}
+}
+
+func (s *BalanceService) AsOf(ctx context.Context, accountID uuid.UUID, params domain.BalanceParams) (domain.Balance, error) {
+	acc, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		return domain.Balance{}, err
+	}
+
+	asOf := params.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
+	balance, err := s.entryRepo.SumUntil(ctx, accountID, asOf)
+	if err != nil {
+		return domain.Balance{}, err
+	}
+
+	return domain.Balance{
+		AccountID: acc.ID,
+		Currency:  acc.Currency,
+		Balance:   balance,
+		AsOf:      asOf.UTC(),
+	}, nil
+}
+
+// Verify reports whether the latest entry's balance_after agrees with the
+// account balance.
+func (s *BalanceService) Verify(ctx context.Context, accountID uuid.UUID) (domain.BalanceCheck, error) {
+	acc, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		return domain.BalanceCheck{}, err
+	}
+
+	check := domain.BalanceCheck{AccountID: acc.ID, Balance: acc.Balance}
+	latest, err := s.entryRepo.Latest(ctx, accountID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return domain.BalanceCheck{}, err
+	default:
+		check.LatestEntryID = &latest.ID
+		check.LatestEntryBalance = latest.BalanceAfter
+	}
+	check.Consistent = check.LatestEntryBalance == acc.Balance
+
+	if !check.Consistent {
+		slog.WarnContext(ctx, "account balance does not match latest entry",
+			"account_id", acc.ID, "balance", acc.Balance, "latest_entry_balance", check.LatestEntryBalance)
+	}
+	return check, nil
+}
+
+func (s *BalanceService) History(ctx context.Context, accountID uuid.UUID, params domain.BalanceHistoryParams) (domain.BalanceHistory, error) {
+	if !params.From.Before(params.To) {
+		return domain.BalanceHistory{}, ErrInvalidRange
+	}
+
+	// Buckets are aligned to interval boundaries, so the first one may start
+	// before the requested from
+	start := domain.TruncateToInterval(params.From, params.Interval)
+	end := params.To.UTC()
+
+	count := 0
+	for t := start; t.Before(end); t = domain.NextInterval(t, params.Interval) {
+		count++
+		if count > maxHistoryBuckets {
+			return domain.BalanceHistory{}, ErrTooManyBuckets
+		}
+	}
+
+	acc, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		return domain.BalanceHistory{}, err
+	}
+
+	opening, err := s.entryRepo.SumUntil(ctx, accountID, start)
+	if err != nil {
+		return domain.BalanceHistory{}, err
+	}
+
+	movements, err := s.entryRepo.Movements(ctx, accountID, start, end, params.Interval)
+	if err != nil {
+		return domain.BalanceHistory{}, err
+	}
+	byStart := make(map[time.Time]domain.BalanceBucket, len(movements))
+	for _, m := range movements {
+		byStart[m.Start] = m
+	}
+
+	buckets := make([]domain.BalanceBucket, 0, count)
+	balance := opening
+	for t := start; t.Before(end); t = domain.NextInterval(t, params.Interval) {
+		m := byStart[t]
+		next := domain.NextInterval(t, params.Interval)
+		if next.After(end) {
+			next = end
+		}
+		bucket := domain.BalanceBucket{
+			Start:          t,
+			End:            next,
+			OpeningBalance: balance,
+			TotalDebits:    m.TotalDebits,
+			TotalCredits:   m.TotalCredits,
+		}
+		balance += m.TotalCredits - m.TotalDebits
+		bucket.ClosingBalance = balance
+		buckets = append(buckets, bucket)
+	}
+
+	return domain.BalanceHistory{
+		AccountID: acc.ID,
+		Currency:  acc.Currency,
+		Interval:  params.Interval,
+		Buckets:   buckets,
+	}, nil
+}
+
+func (s *BalanceService) getAccount(ctx context.Context, id uuid.UUID) (domain.Account, error) {
+	acc, err := s.accountRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Account{}, ErrAccountNotFound
+		}
+		return domain.Account{}, fmt.Errorf("get account: %w", err)
+	}
+	return acc, nil
+}
@@ -33,6 +33,15 @@ func (s *EntryService) GetByID(ctx context.Context, id uuid.UUID) (domain.Entry,
 	return entry, nil
 }
 
-func (s *EntryService) ListByAccount(ctx context.Context, params domain.ListEntriesParams) ([]domain.Entry, error) {
-	return s.repo.ListByAccount(ctx, params)
+func (s *EntryService) ListByAccount(ctx context.Context, params domain.ListEntriesParams) (domain.Page[domain.Entry], error) {
+	after, err := parseCursor(params.Cursor)
+	if err != nil {
+		return domain.Page[domain.Entry]{}, err
+	}
+
+	entries, err := s.repo.ListByAccount(ctx, params, after)
+	if err != nil {
+		return domain.Page[domain.Entry]{}, err
+	}
+	return domain.NewPage(entries, params.Limit, domain.Entry.Cursor), nil
 }
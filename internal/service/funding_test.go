@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/database"
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
+	"github.com/gabrielvieirabra/payments-ledger/internal/worker"
+)
+
+// newTestDB migrates and connects to the database in TEST_DATABASE_URL. Tests
+// using it are skipped when it is not set.
+func newTestDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	if err := database.RunMigrations(url, "../../migrations"); err != nil {
+		t.Fatal(err)
+	}
+	pool, err := database.NewPool(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func fundingBalances(t *testing.T, pool *pgxpool.Pool, currency string) map[uuid.UUID]int64 {
+	t.Helper()
+	rows, err := pool.Query(context.Background(),
+		`SELECT id, balance FROM accounts WHERE is_system AND currency = $1`, currency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	balances := make(map[uuid.UUID]int64)
+	for rows.Next() {
+		var id uuid.UUID
+		var balance int64
+		if err := rows.Scan(&id, &balance); err != nil {
+			t.Fatal(err)
+		}
+		balances[id] = balance
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return balances
+}
+
+func TestTransactionService_ConcurrentFunding(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+
+	accountRepo := repository.NewAccountRepository(pool)
+	entryRepo := repository.NewEntryRepository(pool)
+	wp := worker.NewPool(8, 100)
+	defer wp.Shutdown()
+	svc := NewTransactionService(accountRepo, entryRepo, repository.NewTransactionRepository(pool), wp, 0)
+	balances := NewBalanceService(accountRepo, entryRepo)
+
+	const accounts, deposits, amount = 32, 4, 100
+	ids := make([]uuid.UUID, accounts)
+	for i := range ids {
+		acc, err := accountRepo.Create(ctx, domain.CreateAccountRequest{Owner: "funding-test", Currency: "USD"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = acc.ID
+	}
+	before := fundingBalances(t, pool, "USD")
+	if len(before) < 2 {
+		t.Fatalf("expected USD funding to be spread over several accounts, got %d", len(before))
+	}
+
+	// Every account posts in parallel; within one account deposits come
+	// before the withdrawal so that it is covered
+	var wg sync.WaitGroup
+	errs := make(chan error, accounts*(deposits+1))
+	for _, id := range ids {
+		wg.Go(func() {
+			for range deposits {
+				if _, err := svc.Deposit(ctx, id, domain.CreateFundingRequest{Amount: amount, Currency: "USD"}); err != nil {
+					errs <- err
+					return
+				}
+			}
+			if _, err := svc.Withdraw(ctx, id, domain.CreateFundingRequest{Amount: amount, Currency: "USD"}); err != nil {
+				errs <- err
+			}
+		})
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("unexpected posting error: %v", err)
+	}
+
+	for _, id := range ids {
+		acc, err := accountRepo.GetByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if acc.Balance != (deposits-1)*amount {
+			t.Errorf("expected account %s to hold %d, got %d", id, (deposits-1)*amount, acc.Balance)
+		}
+	}
+
+	after := fundingBalances(t, pool, "USD")
+	var moved int64
+	used := 0
+	for id, balance := range after {
+		if balance == before[id] {
+			continue
+		}
+		used++
+		moved += balance - before[id]
+		check, err := balances.Verify(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !check.Consistent {
+			t.Errorf("expected funding account %s to match its latest entry", id)
+		}
+	}
+	if moved != -accounts*(deposits-1)*amount {
+		t.Errorf("expected funding accounts to move %d, got %d", -accounts*(deposits-1)*amount, moved)
+	}
+	if used < 2 {
+		t.Errorf("expected postings to spread over several funding accounts, used %d", used)
+	}
+}
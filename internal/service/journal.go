@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/auth"
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
+	"github.com/gabrielvieirabra/payments-ledger/internal/worker"
+)
+
+var (
+	ErrUnbalancedJournal = errors.New("journal legs must net to zero per currency")
+	ErrDuplicateLeg      = errors.New("journal legs must reference distinct accounts")
+	ErrAmountOutOfRange  = errors.New("journal leg amounts are out of range")
+)
+
+type JournalService struct {
+	accountRepo     *repository.AccountRepository
+	entryRepo       *repository.EntryRepository
+	transactionRepo *repository.TransactionRepository
+	pool            *worker.Pool
+}
+
+func NewJournalService(
+	accountRepo *repository.AccountRepository,
+	entryRepo *repository.EntryRepository,
+	transactionRepo *repository.TransactionRepository,
+	pool *worker.Pool,
+) *JournalService {
+	return &JournalService{
+		accountRepo:     accountRepo,
+		entryRepo:       entryRepo,
+		transactionRepo: transactionRepo,
+		pool:            pool,
+	}
+}
+
+func (s *JournalService) Post(ctx context.Context, req domain.CreateJournalRequest) (result domain.JournalResult, err error) {
+	defer func() { observeTransfer(domain.TransactionKindJournal, err) }()
+
+	if err := validateLegs(req.Legs); err != nil {
+		return domain.JournalResult{}, err
+	}
+
+	// Shard on the first debited account, mirroring transfers which shard on the source
+	shardKey := req.Legs[0].AccountID
+	for _, leg := range req.Legs {
+		if leg.Amount < 0 {
+			shardKey = leg.AccountID
+			break
+		}
+	}
+
+	var execErr error
+
+	errCh := make(chan error, 1)
+	cmd := worker.Command{
+		AccountID: shardKey,
+		Ctx:       ctx,
+		Exec: func(workerCtx context.Context) error {
+			result, execErr = s.postDirect(withWorkerSpan(ctx, workerCtx), req)
+			return execErr
+		},
+		Err: errCh,
+	}
+
+	if err := s.pool.Submit(cmd); err != nil {
+		return domain.JournalResult{}, fmt.Errorf("submit journal command: %w", err)
+	}
+
+	if err := <-errCh; err != nil {
+		return domain.JournalResult{}, err
+	}
+
+	return result, nil
+}
+
+func validateLegs(legs []domain.JournalLeg) error {
+	seen := make(map[uuid.UUID]struct{}, len(legs))
+	totals := make(map[string]int64)
+	credits := make(map[string]int64)
+	for _, leg := range legs {
+		if _, ok := seen[leg.AccountID]; ok {
+			return ErrDuplicateLeg
+		}
+		seen[leg.AccountID] = struct{}{}
+
+		// A debit of MinInt64 has no positive counterpart to check funds with
+		if leg.Amount == math.MinInt64 {
+			return ErrAmountOutOfRange
+		}
+		// Wrapped sums could net to zero and create money
+		total, ok := addInt64(totals[leg.Currency], leg.Amount)
+		if !ok {
+			return ErrAmountOutOfRange
+		}
+		totals[leg.Currency] = total
+		if leg.Amount > 0 {
+			if credits[leg.Currency], ok = addInt64(credits[leg.Currency], leg.Amount); !ok {
+				return ErrAmountOutOfRange
+			}
+		}
+	}
+
+	for _, total := range totals {
+		if total != 0 {
+			return ErrUnbalancedJournal
+		}
+	}
+	return nil
+}
+
+// addInt64 returns a+b and whether it fits in an int64.
+func addInt64(a, b int64) (int64, bool) {
+	sum := a + b
+	return sum, (sum >= a) == (b >= 0)
+}
+
+// journalAmount is the amount recorded on a journal's transaction: the sum of
+// its credits when all legs share a currency. Amounts in different currencies
+// cannot be added, so multi-currency journals record 0 and are described by
+// their entries. Legs must have passed validateLegs.
+func journalAmount(legs []domain.JournalLeg) int64 {
+	var credited int64
+	for _, leg := range legs {
+		if leg.Currency != legs[0].Currency {
+			return 0
+		}
+		if leg.Amount > 0 {
+			credited += leg.Amount
+		}
+	}
+	return credited
+}
+
+func (s *JournalService) postDirect(ctx context.Context, req domain.CreateJournalRequest) (domain.JournalResult, error) {
+	pool := s.accountRepo.Pool()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return domain.JournalResult{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	// Lock accounts in the same order as transfers to prevent deadlocks
+	ids := make([]uuid.UUID, 0, len(req.Legs))
+	for _, leg := range req.Legs {
+		ids = append(ids, leg.AccountID)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	locked := make(map[uuid.UUID]domain.Account, len(ids))
+	for _, id := range ids {
+		acc, err := s.accountRepo.GetByIDForUpdate(ctx, tx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.JournalResult{}, fmt.Errorf("%w: %s", ErrAccountNotFound, id)
+			}
+			return domain.JournalResult{}, err
+		}
+		locked[id] = acc
+	}
+
+	for _, leg := range req.Legs {
+		acc := locked[leg.AccountID]
+		if acc.Currency != leg.Currency {
+			return domain.JournalResult{}, ErrCurrencyMismatch
+		}
+		if acc.IsSystem {
+			return domain.JournalResult{}, ErrSystemAccount
+		}
+		if err := checkPostable(acc, "account "+acc.ID.String()); err != nil {
+			return domain.JournalResult{}, err
+		}
+		if leg.Amount < 0 && !acc.CanDebit(-leg.Amount) {
+			return domain.JournalResult{}, ErrInsufficientBalance
+		}
+	}
+
+	txn, err := s.transactionRepo.Create(ctx, tx, domain.CreateTransactionParams{
+		Amount:   journalAmount(req.Legs),
+		Kind:     domain.TransactionKindJournal,
+		Status:   domain.TransactionStatusPosted,
+		ClientID: auth.ClientID(ctx),
+	})
+	if err != nil {
+		return domain.JournalResult{}, err
+	}
+
+	result := domain.JournalResult{
+		Transaction: txn,
+		Entries:     make([]domain.Entry, 0, len(req.Legs)),
+		Accounts:    make([]domain.Account, 0, len(req.Legs)),
+	}
+	for _, leg := range req.Legs {
+		updated, err := s.accountRepo.UpdateBalance(ctx, tx, leg.AccountID, leg.Amount)
+		if err != nil {
+			return domain.JournalResult{}, err
+		}
+
+		entry, err := s.entryRepo.Create(ctx, tx, domain.CreateEntryParams{
+			AccountID:     leg.AccountID,
+			TransactionID: txn.ID,
+			Amount:        leg.Amount,
+			BalanceAfter:  updated.Balance,
+		})
+		if err != nil {
+			return domain.JournalResult{}, err
+		}
+
+		result.Entries = append(result.Entries, entry)
+		result.Accounts = append(result.Accounts, updated)
+	}
+
+	if err := commit(ctx, tx, result); err != nil {
+		return domain.JournalResult{}, err
+	}
+
+	return result, nil
+}
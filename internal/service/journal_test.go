@@ -0,0 +1,59 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+)
+
+func legs(currency string, amounts ...int64) []domain.JournalLeg {
+	out := make([]domain.JournalLeg, len(amounts))
+	for i, amount := range amounts {
+		out[i] = domain.JournalLeg{AccountID: uuid.New(), Amount: amount, Currency: currency}
+	}
+	return out
+}
+
+func TestValidateLegs(t *testing.T) {
+	dup := legs("USD", 100, -100)
+	dup[1].AccountID = dup[0].AccountID
+
+	tests := []struct {
+		name string
+		legs []domain.JournalLeg
+		want error
+	}{
+		{"balanced", legs("USD", 100, -60, -40), nil},
+		{"unbalanced", legs("USD", 100, -60), ErrUnbalancedJournal},
+		{"duplicate account", dup, ErrDuplicateLeg},
+		{"balanced per currency", append(legs("USD", 100, -100), legs("EUR", 5, -5)...), nil},
+		{"unbalanced across currencies", append(legs("USD", 100), legs("EUR", -100)...), ErrUnbalancedJournal},
+		// Credits wrap around to zero without overflow checks
+		{"credits wrap to zero", legs("USD", math.MaxInt64, math.MaxInt64, 2), ErrAmountOutOfRange},
+		{"debits wrap to zero", legs("USD", -math.MaxInt64, -math.MaxInt64, -2), ErrAmountOutOfRange},
+		{"minimum debit", legs("USD", math.MinInt64, math.MaxInt64, 1), ErrAmountOutOfRange},
+		{"credits overflow while netting to zero", legs("USD", -math.MaxInt64, -1, math.MaxInt64, 1), ErrAmountOutOfRange},
+		{"largest balanced", legs("USD", math.MaxInt64, -math.MaxInt64), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateLegs(tt.legs); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestJournalAmount(t *testing.T) {
+	if got := journalAmount(legs("USD", 100, -60, -40)); got != 100 {
+		t.Errorf("expected single-currency journal to record its credits, got %d", got)
+	}
+	if got := journalAmount(append(legs("USD", 100, -100), legs("EUR", 5, -5)...)); got != 0 {
+		t.Errorf("expected multi-currency journal to record 0, got %d", got)
+	}
+}
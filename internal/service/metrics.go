@@ -0,0 +1,48 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/gabrielvieirabra/payments-ledger/internal/idempotency"
+	"github.com/gabrielvieirabra/payments-ledger/internal/metrics"
+)
+
+var transferOutcomes = []struct {
+	err     error
+	outcome string
+}{
+	{ErrInsufficientBalance, "insufficient_balance"},
+	{ErrCurrencyMismatch, "currency_mismatch"},
+	{ErrSameAccount, "same_account"},
+	{ErrSystemAccount, "system_account"},
+	{ErrAccountNotFound, "account_not_found"},
+	{ErrAccountFrozen, "account_frozen"},
+	{ErrAccountClosed, "account_closed"},
+	{ErrTransactionNotFound, "transaction_not_found"},
+	{ErrNotReversible, "not_reversible"},
+	{ErrAlreadyReversed, "already_reversed"},
+	{ErrReversalExceedsAmount, "reversal_exceeds_amount"},
+	{ErrTransactionNotPending, "transaction_not_pending"},
+	{ErrAuthorizationExpired, "authorization_expired"},
+	{ErrUnbalancedJournal, "unbalanced_journal"},
+	{ErrDuplicateLeg, "duplicate_leg"},
+	{idempotency.ErrReservationLost, "reservation_lost"},
+}
+
+// transferOutcome names the result of a money movement for metrics.
+// Unexpected failures are grouped under "error".
+func transferOutcome(err error) string {
+	if err == nil {
+		return "success"
+	}
+	for _, o := range transferOutcomes {
+		if errors.Is(err, o.err) {
+			return o.outcome
+		}
+	}
+	return "error"
+}
+
+func observeTransfer(operation string, err error) {
+	metrics.Transfers.WithLabelValues(operation, transferOutcome(err)).Inc()
+}
@@ -0,0 +1,16 @@
+package service
+
+import "github.com/gabrielvieirabra/payments-ledger/internal/domain"
+
+// parseCursor decodes the cursor query parameter; an empty one starts from the
+// first page.
+func parseCursor(raw string) (*domain.Cursor, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	cursor, err := domain.DecodeCursor(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
@@ -5,11 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/gabrielvieirabra/payments-ledger/internal/auth"
 	"github.com/gabrielvieirabra/payments-ledger/internal/domain"
+	"github.com/gabrielvieirabra/payments-ledger/internal/idempotency"
 	"github.com/gabrielvieirabra/payments-ledger/internal/repository"
 	"github.com/gabrielvieirabra/payments-ledger/internal/worker"
 )
@@ -19,13 +23,33 @@ var (
 	ErrInsufficientBalance = errors.New("insufficient balance")
 	ErrSameAccount         = errors.New("cannot transfer to the same account")
 	ErrCurrencyMismatch    = errors.New("currency mismatch between accounts")
+	ErrSystemAccount       = errors.New("operation not allowed on system account")
+
+	ErrNotReversible         = errors.New("only posted transfers, deposits and withdrawals can be reversed")
+	ErrAlreadyReversed       = errors.New("transaction is already fully reversed")
+	ErrReversalExceedsAmount = errors.New("reversal exceeds the remaining transaction amount")
+
+	ErrTransactionNotPending = errors.New("transaction is not a pending authorization")
+	ErrAuthorizationExpired  = errors.New("authorization has expired")
 )
 
+// expireBatchSize bounds how many authorizations one expiry run releases.
+const expireBatchSize = 100
+
+// transferOptions describes how a two-legged posting is recorded.
+type transferOptions struct {
+	kind       string
+	reversalOf *uuid.UUID
+	authorize  bool
+}
+
 type TransactionService struct {
 	accountRepo     *repository.AccountRepository
 	entryRepo       *repository.EntryRepository
 	transactionRepo *repository.TransactionRepository
 	pool            *worker.Pool
+
+	authorizationTTL time.Duration
 }
 
 func NewTransactionService(
@@ -33,28 +57,141 @@ func NewTransactionService(
 	entryRepo *repository.EntryRepository,
 	transactionRepo *repository.TransactionRepository,
 	pool *worker.Pool,
+	authorizationTTL time.Duration,
 ) *TransactionService {
 	return &TransactionService{
-		accountRepo:     accountRepo,
-		entryRepo:       entryRepo,
-		transactionRepo: transactionRepo,
-		pool:            pool,
+		accountRepo:      accountRepo,
+		entryRepo:        entryRepo,
+		transactionRepo:  transactionRepo,
+		pool:             pool,
+		authorizationTTL: authorizationTTL,
 	}
 }
 
-func (s *TransactionService) Transfer(ctx context.Context, req domain.CreateTransactionRequest) (domain.TransactionResult, error) {
+func (s *TransactionService) Transfer(ctx context.Context, req domain.CreateTransactionRequest) (result domain.TransactionResult, err error) {
+	operation := domain.TransactionKindTransfer
+	if req.Mode == domain.TransferModeAuthorize {
+		operation = "authorize"
+	}
+	defer func() { observeTransfer(operation, err) }()
+
 	if req.FromAccountID == req.ToAccountID {
 		return domain.TransactionResult{}, ErrSameAccount
 	}
 
+	return s.submit(ctx, req.FromAccountID, req, transferOptions{
+		kind:      domain.TransactionKindTransfer,
+		authorize: req.Mode == domain.TransferModeAuthorize,
+	})
+}
+
+// Deposit credits an account with money coming from outside the ledger. The
+// matching debit is posted against the system funding account of the currency.
+func (s *TransactionService) Deposit(ctx context.Context, accountID uuid.UUID, req domain.CreateFundingRequest) (result domain.TransactionResult, err error) {
+	defer func() { observeTransfer(domain.TransactionKindDeposit, err) }()
+
+	funding, err := s.accountRepo.GetSystemAccount(ctx, req.Currency, accountID)
+	if err != nil {
+		return domain.TransactionResult{}, err
+	}
+
+	return s.submit(ctx, accountID, domain.CreateTransactionRequest{
+		FromAccountID: funding.ID,
+		ToAccountID:   accountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+	}, transferOptions{kind: domain.TransactionKindDeposit})
+}
+
+// Withdraw debits an account for money leaving the ledger, crediting the
+// system funding account of the currency.
+func (s *TransactionService) Withdraw(ctx context.Context, accountID uuid.UUID, req domain.CreateFundingRequest) (result domain.TransactionResult, err error) {
+	defer func() { observeTransfer(domain.TransactionKindWithdrawal, err) }()
+
+	funding, err := s.accountRepo.GetSystemAccount(ctx, req.Currency, accountID)
+	if err != nil {
+		return domain.TransactionResult{}, err
+	}
+
+	return s.submit(ctx, accountID, domain.CreateTransactionRequest{
+		FromAccountID: accountID,
+		ToAccountID:   funding.ID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+	}, transferOptions{kind: domain.TransactionKindWithdrawal})
+}
+
+// Reverse posts a compensating transfer for all or part of a transaction,
+// moving the money back from the original destination to the source.
+func (s *TransactionService) Reverse(ctx context.Context, id uuid.UUID, req domain.CreateReversalRequest) (result domain.TransactionResult, err error) {
+	defer func() { observeTransfer(domain.TransactionKindReversal, err) }()
+
+	orig, err := s.GetByID(ctx, id)
+	if err != nil {
+		return domain.TransactionResult{}, err
+	}
+
+	switch orig.Kind {
+	case domain.TransactionKindTransfer, domain.TransactionKindDeposit, domain.TransactionKindWithdrawal:
+	default:
+		return domain.TransactionResult{}, ErrNotReversible
+	}
+	if orig.Status != domain.TransactionStatusPosted {
+		return domain.TransactionResult{}, ErrNotReversible
+	}
+
+	if orig.RemainingAmount() <= 0 {
+		return domain.TransactionResult{}, ErrAlreadyReversed
+	}
+	amount := req.Amount
+	if amount == 0 {
+		amount = orig.RemainingAmount()
+	}
+	if amount > orig.RemainingAmount() {
+		return domain.TransactionResult{}, ErrReversalExceedsAmount
+	}
+
+	source, err := s.accountRepo.GetByID(ctx, *orig.ToAccountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.TransactionResult{}, fmt.Errorf("source %w", ErrAccountNotFound)
+		}
+		return domain.TransactionResult{}, err
+	}
+
+	// Shard on the customer side, as deposits and withdrawals do: reversing a
+	// withdrawal moves money out of a funding account
+	shardKey := source.ID
+	if source.IsSystem {
+		shardKey = *orig.FromAccountID
+	}
+
+	return s.submit(ctx, shardKey, domain.CreateTransactionRequest{
+		FromAccountID: *orig.ToAccountID,
+		ToAccountID:   *orig.FromAccountID,
+		Amount:        amount,
+		Currency:      source.Currency,
+	}, transferOptions{kind: domain.TransactionKindReversal, reversalOf: &orig.ID})
+}
+
+// submit runs a transfer on the worker shard owning shardKey, so that all
+// operations on a customer account are serialized on the same queue.
+func (s *TransactionService) submit(ctx context.Context, shardKey uuid.UUID, req domain.CreateTransactionRequest, opts transferOptions) (domain.TransactionResult, error) {
+	return s.run(ctx, shardKey, func(ctx context.Context) (domain.TransactionResult, error) {
+		return s.transferDirect(ctx, req, opts)
+	})
+}
+
+func (s *TransactionService) run(ctx context.Context, shardKey uuid.UUID, fn func(ctx context.Context) (domain.TransactionResult, error)) (domain.TransactionResult, error) {
 	var result domain.TransactionResult
 	var execErr error
 
 	errCh := make(chan error, 1)
 	cmd := worker.Command{
-		AccountID: req.FromAccountID,
+		AccountID: shardKey,
+		Ctx:       ctx,
 		Exec: func(workerCtx context.Context) error {
-			result, execErr = s.transferDirect(ctx, req)
+			result, execErr = fn(withWorkerSpan(ctx, workerCtx))
 			return execErr
 		},
 		Err: errCh,
@@ -71,7 +208,13 @@ func (s *TransactionService) Transfer(ctx context.Context, req domain.CreateTran
 	return result, nil
 }
 
-func (s *TransactionService) transferDirect(ctx context.Context, req domain.CreateTransactionRequest) (domain.TransactionResult, error) {
+// withWorkerSpan keeps the request's values, such as the API client and
+// idempotency scope, while parenting database spans on the worker's span.
+func withWorkerSpan(ctx, workerCtx context.Context) context.Context {
+	return trace.ContextWithSpan(ctx, trace.SpanFromContext(workerCtx))
+}
+
+func (s *TransactionService) transferDirect(ctx context.Context, req domain.CreateTransactionRequest, opts transferOptions) (domain.TransactionResult, error) {
 	fromAcc, err := s.accountRepo.GetByID(ctx, req.FromAccountID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -92,90 +235,357 @@ func (s *TransactionService) transferDirect(ctx context.Context, req domain.Crea
 		return domain.TransactionResult{}, ErrCurrencyMismatch
 	}
 
+	// Funding accounts may only appear on the system side of deposits and
+	// withdrawals, or when reversing one of those
+	if opts.kind != domain.TransactionKindReversal &&
+		((fromAcc.IsSystem && opts.kind != domain.TransactionKindDeposit) ||
+			(toAcc.IsSystem && opts.kind != domain.TransactionKindWithdrawal)) {
+		return domain.TransactionResult{}, ErrSystemAccount
+	}
+
 	pool := s.accountRepo.Pool()
 	tx, err := pool.Begin(ctx)
 	if err != nil {
 		return domain.TransactionResult{}, fmt.Errorf("begin transaction: %w", err)
 	}
-	defer func() {
-		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
-			slog.Error("failed to rollback transaction", "error", rbErr)
+	defer rollback(ctx, tx)
+
+	lockedFrom, lockedTo, err := s.lockPair(ctx, tx, req.FromAccountID, req.ToAccountID)
+	if err != nil {
+		return domain.TransactionResult{}, err
+	}
+	if err := checkPostable(lockedFrom, "source"); err != nil {
+		return domain.TransactionResult{}, err
+	}
+	if err := checkPostable(lockedTo, "destination"); err != nil {
+		return domain.TransactionResult{}, err
+	}
+
+	if !lockedFrom.CanDebit(req.Amount) {
+		return domain.TransactionResult{}, ErrInsufficientBalance
+	}
+
+	// Re-check the refundable amount under lock so concurrent reversals cannot over-refund
+	if opts.reversalOf != nil {
+		orig, err := s.transactionRepo.GetByIDForUpdate(ctx, tx, *opts.reversalOf)
+		if err != nil {
+			return domain.TransactionResult{}, err
+		}
+		if orig.RemainingAmount() <= 0 {
+			return domain.TransactionResult{}, ErrAlreadyReversed
+		}
+		if req.Amount > orig.RemainingAmount() {
+			return domain.TransactionResult{}, ErrReversalExceedsAmount
+		}
+	}
+
+	params := domain.CreateTransactionParams{
+		FromAccountID: &req.FromAccountID,
+		ToAccountID:   &req.ToAccountID,
+		Amount:        req.Amount,
+		Kind:          opts.kind,
+		Status:        domain.TransactionStatusPosted,
+		ReversalOf:    opts.reversalOf,
+		ClientID:      auth.ClientID(ctx),
+	}
+
+	if opts.authorize {
+		expiresAt := time.Now().Add(s.authorizationTTL)
+		params.Status = domain.TransactionStatusPending
+		params.ExpiresAt = &expiresAt
+
+		txn, err := s.transactionRepo.Create(ctx, tx, params)
+		if err != nil {
+			return domain.TransactionResult{}, err
 		}
-	}()
 
-	// Lock accounts in consistent order to prevent deadlocks
-	id1, id2 := req.FromAccountID, req.ToAccountID
-	if id1.String() > id2.String() {
-		id1, id2 = id2, id1
+		// Hold the funds: only the available balance moves until capture
+		heldFrom, err := s.accountRepo.UpdateAvailableBalance(ctx, tx, req.FromAccountID, -req.Amount)
+		if err != nil {
+			return domain.TransactionResult{}, err
+		}
+
+		result := domain.TransactionResult{
+			Transaction: txn,
+			FromAccount: heldFrom,
+			ToAccount:   lockedTo,
+		}
+		if err := commit(ctx, tx, result); err != nil {
+			return domain.TransactionResult{}, err
+		}
+
+		return result, nil
 	}
 
-	// Lock both accounts; use the locked row for balance check
-	locked1, err := s.accountRepo.GetByIDForUpdate(ctx, tx, id1)
+	// Create transaction record
+	txn, err := s.transactionRepo.Create(ctx, tx, params)
 	if err != nil {
 		return domain.TransactionResult{}, err
 	}
-	locked2, err := s.accountRepo.GetByIDForUpdate(ctx, tx, id2)
+
+	result, err := s.postLegs(ctx, tx, txn, req.FromAccountID, req.ToAccountID, req.Amount)
 	if err != nil {
 		return domain.TransactionResult{}, err
 	}
 
-	// Determine which locked row is the source account
-	lockedFrom := locked1
-	if id1 != req.FromAccountID {
-		lockedFrom = locked2
+	if err := commit(ctx, tx, result); err != nil {
+		return domain.TransactionResult{}, err
 	}
 
-	if lockedFrom.Balance < req.Amount {
-		return domain.TransactionResult{}, ErrInsufficientBalance
-	}
+	return result, nil
+}
 
-	// Create transaction record
-	txn, err := s.transactionRepo.Create(ctx, tx, req.FromAccountID, req.ToAccountID, req.Amount)
+// Capture settles a pending authorization, turning its hold into posted entries.
+func (s *TransactionService) Capture(ctx context.Context, id uuid.UUID) (result domain.TransactionResult, err error) {
+	defer func() { observeTransfer("capture", err) }()
+
+	txn, err := s.pendingTransaction(ctx, id)
 	if err != nil {
 		return domain.TransactionResult{}, err
 	}
 
-	// Create entries (debit from source, credit to destination)
-	fromEntry, err := s.entryRepo.Create(ctx, tx, domain.CreateEntryParams{
-		AccountID: req.FromAccountID,
-		Amount:    -req.Amount,
+	return s.run(ctx, *txn.FromAccountID, func(ctx context.Context) (domain.TransactionResult, error) {
+		return s.captureDirect(ctx, txn)
 	})
+}
+
+func (s *TransactionService) captureDirect(ctx context.Context, pending domain.Transaction) (domain.TransactionResult, error) {
+	pool := s.accountRepo.Pool()
+	tx, err := pool.Begin(ctx)
 	if err != nil {
+		return domain.TransactionResult{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	fromID, toID := *pending.FromAccountID, *pending.ToAccountID
+	lockedFrom, lockedTo, err := s.lockPair(ctx, tx, fromID, toID)
+	if err != nil {
+		return domain.TransactionResult{}, err
+	}
+	if err := checkPostable(lockedFrom, "source"); err != nil {
+		return domain.TransactionResult{}, err
+	}
+	if err := checkPostable(lockedTo, "destination"); err != nil {
 		return domain.TransactionResult{}, err
 	}
 
-	toEntry, err := s.entryRepo.Create(ctx, tx, domain.CreateEntryParams{
-		AccountID: req.ToAccountID,
-		Amount:    req.Amount,
+	txn, err := s.transactionRepo.GetByIDForUpdate(ctx, tx, pending.ID)
+	if err != nil {
+		return domain.TransactionResult{}, err
+	}
+	if txn.Status != domain.TransactionStatusPending {
+		return domain.TransactionResult{}, ErrTransactionNotPending
+	}
+	if txn.ExpiresAt != nil && time.Now().After(*txn.ExpiresAt) {
+		return domain.TransactionResult{}, ErrAuthorizationExpired
+	}
+
+	// Release the hold; postLegs then moves posted and available balances together
+	if _, err := s.accountRepo.UpdateAvailableBalance(ctx, tx, fromID, txn.Amount); err != nil {
+		return domain.TransactionResult{}, err
+	}
+
+	txn, err = s.transactionRepo.UpdateStatus(ctx, tx, txn.ID, domain.TransactionStatusPosted)
+	if err != nil {
+		return domain.TransactionResult{}, err
+	}
+
+	result, err := s.postLegs(ctx, tx, txn, fromID, toID, txn.Amount)
+	if err != nil {
+		return domain.TransactionResult{}, err
+	}
+
+	if err := commit(ctx, tx, result); err != nil {
+		return domain.TransactionResult{}, err
+	}
+
+	return result, nil
+}
+
+// Void cancels a pending authorization and releases its hold.
+func (s *TransactionService) Void(ctx context.Context, id uuid.UUID) (result domain.TransactionResult, err error) {
+	defer func() { observeTransfer("void", err) }()
+
+	txn, err := s.pendingTransaction(ctx, id)
+	if err != nil {
+		return domain.TransactionResult{}, err
+	}
+
+	return s.run(ctx, *txn.FromAccountID, func(ctx context.Context) (domain.TransactionResult, error) {
+		return s.releaseDirect(ctx, txn, domain.TransactionStatusVoided)
 	})
+}
+
+// ExpireAuthorizations releases the holds of pending authorizations whose TTL
+// has elapsed. It handles at most one batch per call.
+func (s *TransactionService) ExpireAuthorizations(ctx context.Context) error {
+	expired, err := s.transactionRepo.ListExpiredPending(ctx, expireBatchSize)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	for _, txn := range expired {
+		_, err := s.run(ctx, *txn.FromAccountID, func(ctx context.Context) (domain.TransactionResult, error) {
+			return s.releaseDirect(ctx, txn, domain.TransactionStatusExpired)
+		})
+		if err != nil {
+			// Captured or voided since it was listed
+			if errors.Is(err, ErrTransactionNotPending) {
+				continue
+			}
+			return fmt.Errorf("expire authorization %s: %w", txn.ID, err)
+		}
+		count++
+	}
+
+	if count > 0 {
+		slog.InfoContext(ctx, "expired pending authorizations", "count", count)
+	}
+	return nil
+}
+
+func (s *TransactionService) releaseDirect(ctx context.Context, pending domain.Transaction, status string) (domain.TransactionResult, error) {
+	pool := s.accountRepo.Pool()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return domain.TransactionResult{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer rollback(ctx, tx)
+
+	fromID := *pending.FromAccountID
+	_, lockedTo, err := s.lockPair(ctx, tx, fromID, *pending.ToAccountID)
 	if err != nil {
 		return domain.TransactionResult{}, err
 	}
 
-	// Update balances
-	updatedFrom, err := s.accountRepo.UpdateBalance(ctx, tx, req.FromAccountID, -req.Amount)
+	txn, err := s.transactionRepo.GetByIDForUpdate(ctx, tx, pending.ID)
 	if err != nil {
 		return domain.TransactionResult{}, err
 	}
+	if txn.Status != domain.TransactionStatusPending {
+		return domain.TransactionResult{}, ErrTransactionNotPending
+	}
 
-	updatedTo, err := s.accountRepo.UpdateBalance(ctx, tx, req.ToAccountID, req.Amount)
+	releasedFrom, err := s.accountRepo.UpdateAvailableBalance(ctx, tx, fromID, txn.Amount)
 	if err != nil {
 		return domain.TransactionResult{}, err
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return domain.TransactionResult{}, fmt.Errorf("commit transaction: %w", err)
+	txn, err = s.transactionRepo.UpdateStatus(ctx, tx, txn.ID, status)
+	if err != nil {
+		return domain.TransactionResult{}, err
+	}
+
+	result := domain.TransactionResult{
+		Transaction: txn,
+		FromAccount: releasedFrom,
+		ToAccount:   lockedTo,
+	}
+	if err := commit(ctx, tx, result); err != nil {
+		return domain.TransactionResult{}, err
+	}
+
+	return result, nil
+}
+
+func (s *TransactionService) pendingTransaction(ctx context.Context, id uuid.UUID) (domain.Transaction, error) {
+	txn, err := s.GetByID(ctx, id)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	if txn.Status != domain.TransactionStatusPending {
+		return domain.Transaction{}, ErrTransactionNotPending
+	}
+	return txn, nil
+}
+
+// lockPair locks both accounts of a transfer in a consistent order to prevent
+// deadlocks and returns the locked rows as (from, to).
+func (s *TransactionService) lockPair(ctx context.Context, tx pgx.Tx, fromID, toID uuid.UUID) (domain.Account, domain.Account, error) {
+	id1, id2 := fromID, toID
+	if id1.String() > id2.String() {
+		id1, id2 = id2, id1
+	}
+
+	locked1, err := s.accountRepo.GetByIDForUpdate(ctx, tx, id1)
+	if err != nil {
+		return domain.Account{}, domain.Account{}, err
+	}
+	locked2, err := s.accountRepo.GetByIDForUpdate(ctx, tx, id2)
+	if err != nil {
+		return domain.Account{}, domain.Account{}, err
+	}
+
+	if id1 != fromID {
+		return locked2, locked1, nil
+	}
+	return locked1, locked2, nil
+}
+
+// postLegs writes the debit and credit entries of a two-legged transaction and
+// applies them to both balances.
+func (s *TransactionService) postLegs(ctx context.Context, tx pgx.Tx, txn domain.Transaction, fromID, toID uuid.UUID, amount int64) (domain.TransactionResult, error) {
+	// Update balances first so each entry records the balance it produced
+	updatedFrom, err := s.accountRepo.UpdateBalance(ctx, tx, fromID, -amount)
+	if err != nil {
+		return domain.TransactionResult{}, err
+	}
+
+	updatedTo, err := s.accountRepo.UpdateBalance(ctx, tx, toID, amount)
+	if err != nil {
+		return domain.TransactionResult{}, err
+	}
+
+	// Create entries (debit from source, credit to destination)
+	fromEntry, err := s.entryRepo.Create(ctx, tx, domain.CreateEntryParams{
+		AccountID:     fromID,
+		TransactionID: txn.ID,
+		Amount:        -amount,
+		BalanceAfter:  updatedFrom.Balance,
+	})
+	if err != nil {
+		return domain.TransactionResult{}, err
+	}
+
+	toEntry, err := s.entryRepo.Create(ctx, tx, domain.CreateEntryParams{
+		AccountID:     toID,
+		TransactionID: txn.ID,
+		Amount:        amount,
+		BalanceAfter:  updatedTo.Balance,
+	})
+	if err != nil {
+		return domain.TransactionResult{}, err
 	}
 
 	return domain.TransactionResult{
 		Transaction: txn,
 		FromAccount: updatedFrom,
 		ToAccount:   updatedTo,
-		FromEntry:   fromEntry,
-		ToEntry:     toEntry,
+		FromEntry:   &fromEntry,
+		ToEntry:     &toEntry,
 	}, nil
 }
 
+// commit records result against the request's idempotency key, if it has one,
+// and commits, so that the change and the key are stored atomically.
+func commit(ctx context.Context, tx pgx.Tx, result any) error {
+	if err := idempotency.Record(ctx, tx, result); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	idempotency.Committed(ctx)
+	return nil
+}
+
+func rollback(ctx context.Context, tx pgx.Tx) {
+	if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+		slog.ErrorContext(ctx, "failed to rollback transaction", "error", err)
+	}
+}
+
 func (s *TransactionService) GetByID(ctx context.Context, id uuid.UUID) (domain.Transaction, error) {
 	txn, err := s.transactionRepo.GetByID(ctx, id)
 	if err != nil {
@@ -187,6 +597,23 @@ func (s *TransactionService) GetByID(ctx context.Context, id uuid.UUID) (domain.
 	return txn, nil
 }
 
-func (s *TransactionService) ListByAccount(ctx context.Context, accountID uuid.UUID, limit, offset int32) ([]domain.Transaction, error) {
-	return s.transactionRepo.ListByAccount(ctx, accountID, limit, offset)
+// ListEntries returns the entries posted by a transaction.
+func (s *TransactionService) ListEntries(ctx context.Context, id uuid.UUID) ([]domain.Entry, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.entryRepo.ListByTransaction(ctx, id)
+}
+
+func (s *TransactionService) ListByAccount(ctx context.Context, params domain.ListTransactionsParams) (domain.Page[domain.Transaction], error) {
+	after, err := parseCursor(params.Cursor)
+	if err != nil {
+		return domain.Page[domain.Transaction]{}, err
+	}
+
+	transactions, err := s.transactionRepo.ListByAccount(ctx, params, after)
+	if err != nil {
+		return domain.Page[domain.Transaction]{}, err
+	}
+	return domain.NewPage(transactions, params.Limit, domain.Transaction.Cursor), nil
 }
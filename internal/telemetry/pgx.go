@@ -0,0 +1,74 @@
+package telemetry
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/gabrielvieirabra/payments-ledger/internal/telemetry")
+
+// QueryTracer creates a span for every query and for every wait on the
+// connection pool, so lock waits and pool starvation show up in traces. Set it
+// as the pool's ConnConfig.Tracer.
+type QueryTracer struct{}
+
+var (
+	_ pgx.QueryTracer       = QueryTracer{}
+	_ pgxpool.AcquireTracer = QueryTracer{}
+)
+
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := operationName(data.SQL)
+	ctx, _ = tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(data.SQL),
+		),
+	)
+	return ctx
+}
+
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	} else {
+		span.SetAttributes(attribute.Int64("db.response.rows_affected", data.CommandTag.RowsAffected()))
+	}
+	span.End()
+}
+
+func (QueryTracer) TraceAcquireStart(ctx context.Context, _ *pgxpool.Pool, _ pgxpool.TraceAcquireStartData) context.Context {
+	ctx, _ = tracer.Start(ctx, "pool.acquire", trace.WithAttributes(semconv.DBSystemNamePostgreSQL))
+	return ctx
+}
+
+func (QueryTracer) TraceAcquireEnd(ctx context.Context, _ *pgxpool.Pool, data pgxpool.TraceAcquireEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+	span.End()
+}
+
+// operationName returns the leading SQL keyword, e.g. "SELECT", which keeps
+// span names low-cardinality.
+func operationName(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "query"
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,48 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "transfer")
+	qt := QueryTracer{}
+
+	qctx := qt.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "\n\tselect id FROM accounts WHERE id = $1 FOR UPDATE"})
+	qt.TraceQueryEnd(qctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+
+	qctx = qt.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "UPDATE accounts SET balance = $2"})
+	qt.TraceQueryEnd(qctx, nil, pgx.TraceQueryEndData{Err: errors.New("deadlock detected")})
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+
+	selectSpan, updateSpan := spans[0], spans[1]
+	if selectSpan.Name() != "SELECT" || updateSpan.Name() != "UPDATE" {
+		t.Errorf("expected spans named after the operation, got %q and %q", selectSpan.Name(), updateSpan.Name())
+	}
+	if selectSpan.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("expected query span to be a child of the caller's span")
+	}
+	if selectSpan.Status().Code != codes.Unset {
+		t.Errorf("expected successful query to have no error status, got %v", selectSpan.Status())
+	}
+	if updateSpan.Status().Code != codes.Error || updateSpan.Status().Description != "deadlock detected" {
+		t.Errorf("expected failed query to record the error, got %v", updateSpan.Status())
+	}
+}
@@ -0,0 +1,96 @@
+// Package telemetry configures OpenTelemetry tracing. Spans are created
+// through the global tracer provider, which is a no-op until Setup installs
+// an exporter.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+)
+
+const (
+	ExporterNone = "none"
+	ExporterOTLP = "otlp"
+	ExporterFile = "file"
+)
+
+type Options struct {
+	ServiceName string
+	Environment string
+	// Exporter is one of ExporterNone, ExporterOTLP or ExporterFile. The OTLP
+	// exporter reads the standard OTEL_EXPORTER_OTLP_* variables.
+	Exporter string
+	// FilePath is where ExporterFile writes spans, one JSON object each.
+	FilePath    string
+	SampleRatio float64
+}
+
+// Setup installs the global tracer provider and W3C trace context
+// propagation. The returned function flushes pending spans and must be
+// called on shutdown.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var closeFile func() error
+	switch opts.Exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		otlp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("create otlp exporter: %w", err)
+		}
+		exporter = otlp
+	case ExporterFile:
+		f, err := os.OpenFile(opts.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("open trace file: %w", err)
+		}
+		stdout, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("create file exporter: %w", err)
+		}
+		exporter, closeFile = stdout, f.Close
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", opts.Exporter)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(opts.ServiceName),
+		semconv.DeploymentEnvironmentNameKey.String(opts.Environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeFile != nil {
+			if cerr := closeFile(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Every runs fn once per interval until ctx is cancelled. Failures are logged
+// and retried on the next tick.
+func Every(ctx context.Context, interval time.Duration, name string, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	slog.InfoContext(ctx, "periodic job started", "job", name, "interval", interval)
+	for {
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "periodic job stopped", "job", name)
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				slog.ErrorContext(ctx, "periodic job failed", "job", name, "error", err)
+			}
+		}
+	}
+}
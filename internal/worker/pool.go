@@ -5,15 +5,30 @@ import (
 	"fmt"
 	"hash/fnv"
 	"log/slog"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/gabrielvieirabra/payments-ledger/internal/worker")
+
 type Command struct {
 	AccountID uuid.UUID
-	Exec      func(ctx context.Context) error
-	Err       chan error
+	// Ctx is the submitter's context. Its trace is continued by the spans
+	// covering the time spent queued and executing; cancellation is not
+	// propagated, Exec always runs to completion once dequeued.
+	Ctx  context.Context
+	Exec func(ctx context.Context) error
+	Err  chan error
+
+	queueSpan trace.Span
 }
 
 type Pool struct {
@@ -22,6 +37,9 @@ type Pool struct {
 	wg      sync.WaitGroup
 	ctx     context.Context
 	cancel  context.CancelFunc
+
+	queueDepth   *prometheus.Desc
+	execDuration *prometheus.HistogramVec
 }
 
 func NewPool(workers, queueSize int) *Pool {
@@ -38,6 +56,14 @@ func NewPool(workers, queueSize int) *Pool {
 		queues:  make([]chan Command, workers),
 		ctx:     ctx,
 		cancel:  cancel,
+
+		queueDepth: prometheus.NewDesc("ledger_worker_queue_depth",
+			"Commands waiting in each shard's queue.", []string{"shard"}, nil),
+		execDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ledger_worker_exec_duration_seconds",
+			Help:    "Command execution latency by shard.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"shard"}),
 	}
 
 	for i := range workers {
@@ -59,10 +85,19 @@ func (p *Pool) Submit(cmd Command) error {
 
 	idx := p.shardIndex(cmd.AccountID)
 
+	if cmd.Ctx == nil {
+		cmd.Ctx = context.Background()
+	}
+	_, cmd.queueSpan = tracer.Start(cmd.Ctx, "worker.queue", trace.WithAttributes(
+		attribute.Int("worker.shard", idx),
+		attribute.String("account.id", cmd.AccountID.String()),
+	))
+
 	select {
 	case p.queues[idx] <- cmd:
 		return nil
 	case <-p.ctx.Done():
+		cmd.queueSpan.End()
 		return fmt.Errorf("worker pool shutting down")
 	}
 }
@@ -75,6 +110,7 @@ func (p *Pool) shardIndex(accountID uuid.UUID) int {
 
 func (p *Pool) processQueue(workerID int, ch chan Command) {
 	defer p.wg.Done()
+	execDuration := p.execDuration.WithLabelValues(strconv.Itoa(workerID))
 
 	for {
 		select {
@@ -82,9 +118,9 @@ func (p *Pool) processQueue(workerID int, ch chan Command) {
 			if !ok {
 				return
 			}
-			err := cmd.Exec(p.ctx)
+			err := p.exec(workerID, cmd, execDuration)
 			if err != nil {
-				slog.Error("command execution failed",
+				slog.ErrorContext(cmd.Ctx, "command execution failed",
 					"worker", workerID,
 					"account_id", cmd.AccountID,
 					"error", err,
@@ -98,6 +134,25 @@ func (p *Pool) processQueue(workerID int, ch chan Command) {
 	}
 }
 
+// exec runs cmd with the pool's context, carrying a span that continues the
+// submitter's trace.
+func (p *Pool) exec(workerID int, cmd Command, execDuration prometheus.Observer) error {
+	cmd.queueSpan.End()
+
+	ctx := trace.ContextWithSpanContext(p.ctx, trace.SpanContextFromContext(cmd.Ctx))
+	ctx, span := tracer.Start(ctx, "worker.exec", trace.WithAttributes(attribute.Int("worker.shard", workerID)))
+	defer span.End()
+
+	start := time.Now()
+	err := cmd.Exec(ctx)
+	execDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
 func (p *Pool) drainQueue(ch chan Command) {
 	for {
 		select {
@@ -105,6 +160,7 @@ func (p *Pool) drainQueue(ch chan Command) {
 			if !ok {
 				return
 			}
+			cmd.queueSpan.End()
 			cmd.Err <- fmt.Errorf("worker pool shutting down")
 		default:
 			return
@@ -122,3 +178,17 @@ func (p *Pool) Shutdown() {
 	p.wg.Wait()
 	slog.Info("worker pool shut down")
 }
+
+// Describe and Collect export per-shard queue depth and execution latency,
+// so a Pool can be registered as a prometheus.Collector.
+func (p *Pool) Describe(ch chan<- *prometheus.Desc) {
+	ch <- p.queueDepth
+	p.execDuration.Describe(ch)
+}
+
+func (p *Pool) Collect(ch chan<- prometheus.Metric) {
+	for i, q := range p.queues {
+		ch <- prometheus.MustNewConstMetric(p.queueDepth, prometheus.GaugeValue, float64(len(q)), strconv.Itoa(i))
+	}
+	p.execDuration.Collect(ch)
+}
@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestPool_PropagatesTraceContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	pool := NewPool(2, 1)
+	defer pool.Shutdown()
+
+	ctx, request := otel.Tracer("test").Start(context.Background(), "request")
+	var execTrace trace.SpanContext
+	errCh := make(chan error, 1)
+	err := pool.Submit(Command{
+		AccountID: uuid.New(),
+		Ctx:       ctx,
+		Exec: func(ctx context.Context) error {
+			execTrace = trace.SpanContextFromContext(ctx)
+			return nil
+		},
+		Err: errCh,
+	})
+	if err != nil {
+		t.Fatalf("unexpected submit error: %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("unexpected exec error: %v", err)
+	}
+	request.End()
+
+	if execTrace.TraceID() != request.SpanContext().TraceID() {
+		t.Fatal("expected Exec to run in the submitter's trace")
+	}
+
+	parents := make(map[string]trace.SpanID)
+	for _, span := range recorder.Ended() {
+		parents[span.Name()] = span.Parent().SpanID()
+	}
+	for _, name := range []string{"worker.queue", "worker.exec"} {
+		parent, ok := parents[name]
+		if !ok {
+			t.Errorf("expected a %s span", name)
+			continue
+		}
+		if parent != request.SpanContext().SpanID() {
+			t.Errorf("expected %s to be a child of the request span", name)
+		}
+	}
+}
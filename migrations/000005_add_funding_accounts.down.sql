@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_transactions_kind;
+ALTER TABLE transactions DROP COLUMN IF EXISTS kind;
+
+DELETE FROM accounts WHERE is_system;
+DROP INDEX IF EXISTS idx_accounts_system_currency;
+ALTER TABLE accounts DROP COLUMN IF EXISTS is_system;
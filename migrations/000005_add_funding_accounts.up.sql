@@ -0,0 +1,12 @@
+ALTER TABLE accounts ADD COLUMN is_system BOOLEAN NOT NULL DEFAULT false;
+
+CREATE UNIQUE INDEX idx_accounts_system_currency ON accounts (currency) WHERE is_system;
+
+INSERT INTO accounts (owner, currency, is_system) VALUES
+    ('external-funding', 'USD', true),
+    ('external-funding', 'EUR', true),
+    ('external-funding', 'BRL', true);
+
+ALTER TABLE transactions ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'transfer';
+
+CREATE INDEX idx_transactions_kind ON transactions (kind);
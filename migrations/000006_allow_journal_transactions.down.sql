@@ -0,0 +1,2 @@
+ALTER TABLE transactions ALTER COLUMN to_account_id SET NOT NULL;
+ALTER TABLE transactions ALTER COLUMN from_account_id SET NOT NULL;
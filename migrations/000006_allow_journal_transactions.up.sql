@@ -0,0 +1,4 @@
+-- Journal transactions have any number of legs, recorded as entries, so the
+-- single source/destination pair is only set for two-legged transactions.
+ALTER TABLE transactions ALTER COLUMN from_account_id DROP NOT NULL;
+ALTER TABLE transactions ALTER COLUMN to_account_id DROP NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_entries_transaction_id;
+ALTER TABLE entries DROP COLUMN IF EXISTS transaction_id;
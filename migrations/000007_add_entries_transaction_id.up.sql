@@ -0,0 +1,39 @@
+ALTER TABLE entries ADD COLUMN transaction_id UUID REFERENCES transactions (id);
+
+-- Transfers write the transaction and its entries in one database transaction,
+-- so both sides share the same created_at (now() is fixed at BEGIN). An entry
+-- belongs to a transaction with that timestamp when it debits the source
+-- account (negative amount) or credits the destination (positive amount) by
+-- the transferred amount. Entries matching more than one transaction cannot
+-- be told apart and are left NULL; journal legs have no source/destination
+-- pair and are left NULL too. transaction_id therefore stays nullable: only
+-- historical entries lack it, every entry posted since carries it.
+WITH candidates AS (
+    SELECT e.id AS entry_id, t.id AS transaction_id,
+           count(*) OVER (PARTITION BY e.id) AS matches
+    FROM entries e
+    JOIN transactions t ON t.created_at = e.created_at
+    WHERE e.transaction_id IS NULL
+      AND (   (e.account_id = t.from_account_id AND e.amount = -t.amount)
+           OR (e.account_id = t.to_account_id AND e.amount = t.amount))
+)
+UPDATE entries e
+SET transaction_id = c.transaction_id
+FROM candidates c
+WHERE e.id = c.entry_id
+  AND c.matches = 1;
+
+-- Report what could not be linked; the rows can be listed with
+-- SELECT * FROM entries WHERE transaction_id IS NULL
+DO $$
+DECLARE
+    unlinked BIGINT;
+BEGIN
+    SELECT count(*) INTO unlinked FROM entries WHERE transaction_id IS NULL;
+    IF unlinked > 0 THEN
+        RAISE WARNING '% historical entries could not be linked to a transaction and keep a NULL transaction_id', unlinked;
+    END IF;
+END
+$$;
+
+CREATE INDEX idx_entries_transaction_id ON entries (transaction_id);
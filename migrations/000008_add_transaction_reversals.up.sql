@@ -0,0 +1,3 @@
+ALTER TABLE transactions ADD COLUMN reversal_of UUID REFERENCES transactions (id);
+
+CREATE INDEX idx_transactions_reversal_of ON transactions (reversal_of);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_transactions_pending_expires_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS status;
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS available_balance;
@@ -0,0 +1,8 @@
+ALTER TABLE accounts ADD COLUMN available_balance BIGINT NOT NULL DEFAULT 0;
+
+UPDATE accounts SET available_balance = balance;
+
+ALTER TABLE transactions ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'posted';
+ALTER TABLE transactions ADD COLUMN expires_at TIMESTAMPTZ;
+
+CREATE INDEX idx_transactions_pending_expires_at ON transactions (expires_at) WHERE status = 'pending';
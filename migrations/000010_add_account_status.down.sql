@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_accounts_status;
+ALTER TABLE accounts DROP COLUMN IF EXISTS status;
@@ -0,0 +1,3 @@
+ALTER TABLE accounts ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active';
+
+CREATE INDEX idx_accounts_status ON accounts (status);
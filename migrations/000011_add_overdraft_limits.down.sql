@@ -0,0 +1,2 @@
+ALTER TABLE accounts DROP COLUMN IF EXISTS unlimited_overdraft;
+ALTER TABLE accounts DROP COLUMN IF EXISTS overdraft_limit;
@@ -0,0 +1,5 @@
+ALTER TABLE accounts ADD COLUMN overdraft_limit BIGINT NOT NULL DEFAULT 0 CHECK (overdraft_limit >= 0);
+ALTER TABLE accounts ADD COLUMN unlimited_overdraft BOOLEAN NOT NULL DEFAULT false;
+
+-- Funding accounts mirror money held outside the ledger and may go arbitrarily negative
+UPDATE accounts SET unlimited_overdraft = true WHERE is_system;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_transactions_to_created_at_id;
+DROP INDEX IF EXISTS idx_transactions_from_created_at_id;
+DROP INDEX IF EXISTS idx_entries_account_created_at_id;
+DROP INDEX IF EXISTS idx_accounts_created_at_id;
@@ -0,0 +1,4 @@
+CREATE INDEX idx_accounts_created_at_id ON accounts (created_at DESC, id DESC);
+CREATE INDEX idx_entries_account_created_at_id ON entries (account_id, created_at DESC, id DESC);
+CREATE INDEX idx_transactions_from_created_at_id ON transactions (from_account_id, created_at DESC, id DESC);
+CREATE INDEX idx_transactions_to_created_at_id ON transactions (to_account_id, created_at DESC, id DESC);
@@ -0,0 +1,20 @@
+ALTER TABLE entries ADD COLUMN balance_after BIGINT;
+
+-- Walk each account's entries backwards from its current balance, so the
+-- latest entry always agrees with accounts.balance even for balances seeded
+-- outside the ledger.
+UPDATE entries e
+SET balance_after = r.balance_after
+FROM (
+    SELECT en.id,
+           a.balance - COALESCE(SUM(en.amount) OVER (
+               PARTITION BY en.account_id
+               ORDER BY en.created_at DESC, en.id DESC
+               ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING
+           ), 0) AS balance_after
+    FROM entries en
+    JOIN accounts a ON a.id = en.account_id
+) r
+WHERE e.id = r.id;
+
+ALTER TABLE entries ALTER COLUMN balance_after SET NOT NULL;
@@ -0,0 +1,2 @@
+-- Keys stored before fingerprinting have no hash and are replayed as before.
+ALTER TABLE idempotency_keys ADD COLUMN request_hash VARCHAR(64);
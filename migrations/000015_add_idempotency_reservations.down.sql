@@ -0,0 +1,7 @@
+DELETE FROM idempotency_keys WHERE status = 'processing';
+
+ALTER TABLE idempotency_keys
+    ALTER COLUMN response_body SET NOT NULL,
+    ALTER COLUMN status_code SET NOT NULL,
+    DROP COLUMN IF EXISTS locked_at,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,7 @@
+-- A key is reserved as 'processing' before the handler runs and filled in
+-- with the response once it completes.
+ALTER TABLE idempotency_keys
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'completed',
+    ADD COLUMN locked_at TIMESTAMPTZ,
+    ALTER COLUMN status_code DROP NOT NULL,
+    ALTER COLUMN response_body DROP NOT NULL;
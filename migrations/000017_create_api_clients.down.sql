@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_idempotency_keys_unique;
+DELETE FROM idempotency_keys WHERE client_id IS NOT NULL;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS client_id;
+CREATE UNIQUE INDEX idx_idempotency_keys_unique ON idempotency_keys (idempotency_key, method, path);
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS client_id;
+
+DROP TABLE IF EXISTS api_clients;
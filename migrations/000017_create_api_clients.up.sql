@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS api_clients (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(255) NOT NULL UNIQUE,
+    key_prefix  VARCHAR(16)  NOT NULL,
+    key_hash    CHAR(64)     NOT NULL UNIQUE,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    revoked_at  TIMESTAMPTZ
+);
+
+-- Transactions created before authentication have no client.
+ALTER TABLE transactions ADD COLUMN client_id UUID REFERENCES api_clients (id);
+
+-- Idempotency keys are scoped per client so that two clients can never see
+-- each other's responses. Anonymous keys (auth disabled) share the NULL scope.
+ALTER TABLE idempotency_keys ADD COLUMN client_id UUID REFERENCES api_clients (id) ON DELETE CASCADE;
+
+DROP INDEX idx_idempotency_keys_unique;
+CREATE UNIQUE INDEX idx_idempotency_keys_unique
+    ON idempotency_keys (client_id, idempotency_key, method, path) NULLS NOT DISTINCT;
@@ -0,0 +1,4 @@
+-- Clients created before roles existed keep full access.
+ALTER TABLE api_clients ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'operator'
+    CHECK (role IN ('viewer', 'processor', 'operator'));
+ALTER TABLE api_clients ALTER COLUMN role DROP DEFAULT;
@@ -0,0 +1,12 @@
+-- Shared secrets for HMAC-signed requests. They are stored in plain text
+-- because verifying a signature needs the secret itself. A client may have
+-- several active secrets while rotating.
+CREATE TABLE IF NOT EXISTS api_client_secrets (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id   UUID        NOT NULL REFERENCES api_clients (id) ON DELETE CASCADE,
+    secret      TEXT        NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at  TIMESTAMPTZ
+);
+
+CREATE INDEX idx_api_client_secrets_client_id ON api_client_secrets (client_id) WHERE revoked_at IS NULL;
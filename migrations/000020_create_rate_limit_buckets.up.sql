@@ -0,0 +1,10 @@
+-- Token buckets shared by all replicas. full_at is when the bucket will have
+-- refilled, after which the row carries no information and can be purged.
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets (
+    key         TEXT PRIMARY KEY,
+    tokens      DOUBLE PRECISION NOT NULL,
+    updated_at  TIMESTAMPTZ      NOT NULL,
+    full_at     TIMESTAMPTZ      NOT NULL
+);
+
+CREATE INDEX idx_rate_limit_buckets_full_at ON rate_limit_buckets (full_at);
@@ -0,0 +1,40 @@
+-- Fold the extra shards back into shard 0. balance_after of the moved entries
+-- still describes the shard they were posted to.
+UPDATE entries e
+SET account_id = s0.id
+FROM accounts s, accounts s0
+WHERE e.account_id = s.id
+  AND s.is_system AND s.system_shard > 0
+  AND s0.is_system AND s0.system_shard = 0 AND s0.currency = s.currency;
+
+UPDATE transactions t
+SET from_account_id = s0.id
+FROM accounts s, accounts s0
+WHERE t.from_account_id = s.id
+  AND s.is_system AND s.system_shard > 0
+  AND s0.is_system AND s0.system_shard = 0 AND s0.currency = s.currency;
+
+UPDATE transactions t
+SET to_account_id = s0.id
+FROM accounts s, accounts s0
+WHERE t.to_account_id = s.id
+  AND s.is_system AND s.system_shard > 0
+  AND s0.is_system AND s0.system_shard = 0 AND s0.currency = s.currency;
+
+UPDATE accounts s0
+SET balance = s0.balance + shards.balance,
+    available_balance = s0.available_balance + shards.available_balance
+FROM (
+    SELECT currency, SUM(balance) AS balance, SUM(available_balance) AS available_balance
+    FROM accounts
+    WHERE is_system AND system_shard > 0
+    GROUP BY currency
+) shards
+WHERE s0.is_system AND s0.system_shard = 0 AND s0.currency = shards.currency;
+
+DELETE FROM accounts WHERE is_system AND system_shard > 0;
+
+DROP INDEX IF EXISTS idx_accounts_system_currency_shard;
+CREATE UNIQUE INDEX idx_accounts_system_currency ON accounts (currency) WHERE is_system;
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS system_shard;
@@ -0,0 +1,16 @@
+-- Every deposit and withdrawal locks its currency's funding account, so a
+-- single row serialized them all. Each currency now has several funding
+-- accounts; a posting uses the one picked by its customer account, and the
+-- money held outside the ledger is the sum over a currency's shards.
+ALTER TABLE accounts ADD COLUMN system_shard SMALLINT;
+
+UPDATE accounts SET system_shard = 0 WHERE is_system;
+
+DROP INDEX idx_accounts_system_currency;
+CREATE UNIQUE INDEX idx_accounts_system_currency_shard ON accounts (currency, system_shard) WHERE is_system;
+
+INSERT INTO accounts (owner, currency, is_system, unlimited_overdraft, system_shard)
+SELECT 'external-funding', a.currency, true, true, s.shard
+FROM accounts a
+CROSS JOIN generate_series(1, 7) AS s (shard)
+WHERE a.is_system;